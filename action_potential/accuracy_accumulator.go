@@ -26,26 +26,123 @@
 package action_potential
 
 import (
+	"github.com/absoludity/go-neuron/clock"
+	"math"
+	"sync"
 	"time"
 )
 
 // An AccuracyAggregator encapsulates an action potential and
-// records the average skew
+// records the skew between the time at which potential is added
+// and the (real) time at which it is actually added. A positive
+// skew is late, a negative skew early.
+//
+// AverageDelta and Count are kept for compatibility; use Snapshot
+// to read the statistics while a simulation is running.
 type AccuracyAccumulator struct {
 	ActionPotential
 	AverageDelta time.Duration
 	Count        int64
+	// Clock provides the real time against which the skew is
+	// measured. The nil value uses the system clock.
+	Clock clock.Clock
+
+	mutex     sync.Mutex
+	min, max  time.Duration
+	mean, m2  float64
+	late      int64
+	early     int64
+	histogram DurationHistogram
+}
+
+// An AccuracySnapshot is a copy of the statistics of an
+// AccuracyAccumulator at a point in time.
+type AccuracySnapshot struct {
+	Count  int64
+	Mean   time.Duration
+	Min    time.Duration
+	Max    time.Duration
+	StdDev time.Duration
+	// Late and Early count the potential added after and before
+	// the requested time respectively.
+	Late  int64
+	Early int64
+
+	histogram DurationHistogram
+}
+
+// Percentile returns an estimate of the skew below which the given
+// percentage (0-100) of the skews fall.
+func (s *AccuracySnapshot) Percentile(percent float64) time.Duration {
+	p := s.histogram.Percentile(percent)
+	// The estimate is within a bucket, so may fall just outside the
+	// actual range.
+	if p < s.Min {
+		return s.Min
+	}
+	if p > s.Max {
+		return s.Max
+	}
+	return p
 }
 
 func NewAccuracyAccumulator(ap ActionPotential) *AccuracyAccumulator {
-	return &AccuracyAccumulator{ap, 0, 0}
+	return &AccuracyAccumulator{ActionPotential: ap}
 }
 
-func (f *AccuracyAccumulator) AddPotentialAt(p Potential, t time.Time) (Potential, bool) {
-	now := time.Now()
-	potential, fired := f.ActionPotential.AddPotentialAt(p, t)
-	total_skew := int64(f.AverageDelta)*f.Count + int64(now.Sub(t))
+func (f *AccuracyAccumulator) record(skew time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	total_skew := int64(f.AverageDelta)*f.Count + int64(skew)
 	f.Count += 1
 	f.AverageDelta = time.Duration(total_skew / f.Count)
+
+	if f.Count == 1 || skew < f.min {
+		f.min = skew
+	}
+	if f.Count == 1 || skew > f.max {
+		f.max = skew
+	}
+	// Welford's online algorithm for the variance.
+	delta := float64(skew) - f.mean
+	f.mean += delta / float64(f.Count)
+	f.m2 += delta * (float64(skew) - f.mean)
+	switch {
+	case skew > 0:
+		f.late += 1
+	case skew < 0:
+		f.early += 1
+	}
+	f.histogram.Add(skew)
+}
+
+// Snapshot returns a copy of the statistics so far. It is safe to
+// call while potential is being added.
+func (f *AccuracyAccumulator) Snapshot() AccuracySnapshot {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	s := AccuracySnapshot{
+		Count:     f.Count,
+		Mean:      time.Duration(f.mean),
+		Min:       f.min,
+		Max:       f.max,
+		Late:      f.late,
+		Early:     f.early,
+		histogram: f.histogram,
+	}
+	if f.Count > 1 {
+		s.StdDev = time.Duration(math.Sqrt(f.m2 / float64(f.Count-1)))
+	}
+	return s
+}
+
+func (f *AccuracyAccumulator) AddPotentialAt(p Potential, t time.Time) (Potential, bool) {
+	now := clock.OrSystem(f.Clock).Now()
+	potential, fired := f.ActionPotential.AddPotentialAt(p, t)
+	f.record(now.Sub(t))
 	return potential, fired
 }
+
+func (f *AccuracyAccumulator) AddPotential(p Potential) (Potential, bool) {
+	return f.AddPotentialAt(p, clock.OrSystem(f.Clock).Now())
+}
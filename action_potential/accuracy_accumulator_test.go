@@ -26,6 +26,7 @@
 package action_potential
 
 import (
+	"sync"
 	"testing"
 	"time"
 )
@@ -62,3 +63,108 @@ func TestAccuracyAggretator(t *testing.T) {
 			accum.AverageDelta, expected_average-slop, expected_average+slop)
 	}
 }
+
+func within(actual, expected, slop time.Duration) bool {
+	return actual >= expected-slop && actual <= expected+slop
+}
+
+func TestAccuracyAccumulatorSnapshot(t *testing.T) {
+	interval := 100 * time.Millisecond
+	delays := []time.Duration{
+		-1 * interval,
+		1 * interval,
+		2 * interval,
+		3 * interval,
+	}
+	accum := NewAccuracyAccumulator(new(Simple))
+
+	for _, delay := range delays {
+		accum.AddPotentialAt(5, time.Now().Add(-delay))
+	}
+	snapshot := accum.Snapshot()
+
+	slop := 1 * time.Millisecond
+	if snapshot.Count != int64(len(delays)) {
+		t.Errorf("Expected Count=%d but was %d.", len(delays), snapshot.Count)
+	}
+	if !within(snapshot.Min, -interval, slop) || !within(snapshot.Max, 3*interval, slop) {
+		t.Errorf("Expected range [%s,%s], actual [%s,%s].",
+			-interval, 3*interval, snapshot.Min, snapshot.Max)
+	}
+	if !within(snapshot.Mean, average(delays), slop) {
+		t.Errorf("Expected mean %s, actual %s.", average(delays), snapshot.Mean)
+	}
+	// The sample standard deviation of -1, 1, 2 and 3 is ~1.708.
+	if !within(snapshot.StdDev, 1708*interval/1000, slop) {
+		t.Errorf("Expected standard deviation %s, actual %s.",
+			1708*interval/1000, snapshot.StdDev)
+	}
+	if snapshot.Late != 3 || snapshot.Early != 1 {
+		t.Errorf("Expected 3 late and 1 early, actual %d late and %d early.",
+			snapshot.Late, snapshot.Early)
+	}
+	percentiles := []struct {
+		percent  float64
+		expected time.Duration
+	}{
+		{0, -interval},
+		{25, -interval},
+		{50, interval},
+		{75, 2 * interval},
+		{100, 3 * interval},
+	}
+	for _, p := range percentiles {
+		actual := snapshot.Percentile(p.percent)
+		bucket_slop := p.expected / 16
+		if bucket_slop < 0 {
+			bucket_slop = -bucket_slop
+		}
+		if !within(actual, p.expected, bucket_slop+slop) {
+			t.Errorf("Expected %.0fth percentile %s, actual %s.",
+				p.percent, p.expected, actual)
+		}
+	}
+}
+
+func TestAccuracyAccumulatorAddPotential(t *testing.T) {
+	accum := NewAccuracyAccumulator(new(Simple))
+
+	accum.AddPotential(5)
+
+	snapshot := accum.Snapshot()
+	if snapshot.Count != 1 {
+		t.Errorf("Expected Count=1 but was %d.", snapshot.Count)
+	}
+	if !within(snapshot.Mean, 0, time.Millisecond) {
+		t.Errorf("Expected a negligible skew, actual %s.", snapshot.Mean)
+	}
+}
+
+func TestAccuracyAccumulatorConcurrentSnapshot(t *testing.T) {
+	// Run with -race to check Snapshot is safe during a simulation.
+	accum := NewAccuracyAccumulator(NewSynchronized(new(Simple)))
+	done := make(chan bool)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				accum.Snapshot()
+			}
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		accum.AddPotential(0)
+	}
+	close(done)
+	wg.Wait()
+
+	if snapshot := accum.Snapshot(); snapshot.Count != 1000 {
+		t.Errorf("Expected Count=1000 but was %d.", snapshot.Count)
+	}
+}
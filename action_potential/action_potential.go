@@ -46,10 +46,76 @@ type ActionPotential interface {
 	AddPotentialAt(Potential, time.Time) (Potential, bool)
 }
 
+// A Spontaneous action potential can fire without potential being
+// added, such as when driven by a constant current or when its
+// potential keeps rising after an input. NextSpike returns the time
+// at which it will next fire if no further potential is added, or an
+// earlier time at which to ask again if it looks no further ahead, and
+// false if it will not fire. Adding no potential at that time reports
+// the spike, if any, so a neuron can be woken for each spike as it
+// happens rather than learning of it on its next input.
+type Spontaneous interface {
+	ActionPotential
+	NextSpike() (time.Time, bool)
+}
+
+// A Retimed action potential can fire at a time other than that of
+// the input which reports it, such as a History given a late input
+// which causes a later input to fire. FiredAt returns the time of the
+// firing most recently reported, or the zero time if there is none.
+type Retimed interface {
+	ActionPotential
+	FiredAt() time.Time
+}
+
+// A prediction caches the result of NextSpike for a model integrated
+// over time, which remains valid until potential is added, the model's
+// current changes or the model reaches the predicted time.
+type prediction struct {
+	// since is the time of the most recent input or change of
+	// current, from which a model looks no further than its horizon.
+	since   time.Time
+	valid   bool
+	current float64
+	next    time.Time
+	ok      bool
+}
+
+// cached returns the cached prediction of a model with the given
+// current, last changed at the given time, and whether it is valid.
+func (p *prediction) cached(current float64, last_change time.Time) (time.Time, bool, bool) {
+	if p.valid && p.current != current {
+		p.restart(last_change)
+	}
+	if !p.valid || p.ok && !last_change.Before(p.next) {
+		return time.Time{}, false, false
+	}
+	return p.next, p.ok, true
+}
+
+// store caches and returns the prediction for the given current.
+func (p *prediction) store(current float64, next time.Time, ok bool) (time.Time, bool) {
+	p.valid, p.current, p.next, p.ok = true, current, next, ok
+	return next, ok
+}
+
+// restart discards the prediction after input at the given time.
+func (p *prediction) restart(t time.Time) {
+	p.since, p.valid = t, false
+}
+
+// horizon returns the time after which a model which last spiked at
+// the given time looks no further ahead.
+func (p *prediction) horizon(last_spike time.Time, ahead time.Duration) time.Time {
+	if last_spike.After(p.since) {
+		return last_spike.Add(ahead)
+	}
+	return p.since.Add(ahead)
+}
+
 // Typically 15mV above the resting potential.
-// Move these into a subclass perhaps, so it's possible
-// to have different classes of neurons without requiring
-// each neuron to include the values.
+// These are the defaults; a Simple can be given its own
+// values with SimpleParams.
 const (
 	REST_POTENTIAL       Potential = 0
 	THRESHOLD_POTENTIAL  Potential = 15
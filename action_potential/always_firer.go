@@ -26,6 +26,7 @@
 package action_potential
 
 import (
+	"github.com/absoludity/go-neuron/clock"
 	"time"
 )
 
@@ -33,10 +34,13 @@ import (
 // on every call to AddPotentialAt().
 type AlwaysFirer struct {
 	ActionPotential
+	// Clock provides the time for GetPotential and AddPotential.
+	// The nil value uses the system clock.
+	Clock clock.Clock
 }
 
 func NewAlwaysFirer(ap ActionPotential) *AlwaysFirer {
-	return &AlwaysFirer{ap}
+	return &AlwaysFirer{ActionPotential: ap}
 }
 
 func (f *AlwaysFirer) AddPotentialAt(p Potential, t time.Time) (Potential, bool) {
@@ -45,5 +49,5 @@ func (f *AlwaysFirer) AddPotentialAt(p Potential, t time.Time) (Potential, bool)
 }
 
 func (f *AlwaysFirer) AddPotential(p Potential) (Potential, bool) {
-	return f.AddPotentialAt(p, time.Now())
+	return f.AddPotentialAt(p, clock.OrSystem(f.Clock).Now())
 }
@@ -0,0 +1,394 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ENCODING_VERSION is written at the start of every binary and JSON
+// encoding, so that snapshots can still be read if the format changes.
+const ENCODING_VERSION = 1
+
+func checkVersion(version int) error {
+	if version != ENCODING_VERSION {
+		return fmt.Errorf("unsupported encoding version %d (expected %d)",
+			version, ENCODING_VERSION)
+	}
+	return nil
+}
+
+// MarshalText encodes the activation state as its name.
+func (as ActivationState) MarshalText() ([]byte, error) {
+	if as < DEACTIVATED || as > INACTIVATED {
+		return nil, fmt.Errorf("unknown activation state %d", int(as))
+	}
+	return []byte(as.String()), nil
+}
+
+// UnmarshalText decodes an activation state from its name.
+func (as *ActivationState) UnmarshalText(text []byte) error {
+	for _, state := range []ActivationState{DEACTIVATED, ACTIVATED, INACTIVATED} {
+		if state.String() == string(text) {
+			*as = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown activation state %q", text)
+}
+
+// writePotentialState writes the state, the potential and the length
+// prefixed time of the last change.
+func writePotentialState(buf *bytes.Buffer, ps PotentialState) error {
+	last_change, err := ps.last_change.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(byte(ps.state))
+	binary.Write(buf, binary.BigEndian, ps.last_potential)
+	buf.WriteByte(byte(len(last_change)))
+	buf.Write(last_change)
+	return nil
+}
+
+func readPotentialState(r *bytes.Reader) (PotentialState, error) {
+	var ps PotentialState
+	state, err := r.ReadByte()
+	if err != nil {
+		return ps, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &ps.last_potential); err != nil {
+		return ps, err
+	}
+	length, err := r.ReadByte()
+	if err != nil {
+		return ps, err
+	}
+	last_change := make([]byte, length)
+	if _, err := io.ReadFull(r, last_change); err != nil {
+		return ps, err
+	}
+	if err := ps.last_change.UnmarshalBinary(last_change); err != nil {
+		return ps, err
+	}
+	ps.state = ActivationState(state)
+	if _, err := ps.state.MarshalText(); err != nil {
+		return ps, err
+	}
+	return ps, nil
+}
+
+// readVersion reads and checks the version at the start of a binary
+// encoding.
+func readVersion(r *bytes.Reader) error {
+	version, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	return checkVersion(int(version))
+}
+
+// checkFullyRead returns an error if data remains after decoding.
+func checkFullyRead(r *bytes.Reader) error {
+	if r.Len() != 0 {
+		return fmt.Errorf("%d unexpected bytes after encoding", r.Len())
+	}
+	return nil
+}
+
+// MarshalBinary encodes the potential state, so that it can be
+// restored with UnmarshalBinary.
+func (ps PotentialState) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(ENCODING_VERSION)
+	if err := writePotentialState(&buf, ps); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores a potential state encoded by MarshalBinary.
+func (ps *PotentialState) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if err := readVersion(r); err != nil {
+		return err
+	}
+	decoded, err := readPotentialState(r)
+	if err != nil {
+		return err
+	}
+	if err := checkFullyRead(r); err != nil {
+		return err
+	}
+	*ps = decoded
+	return nil
+}
+
+type potentialStateJSON struct {
+	Version    int             `json:"version"`
+	Potential  Potential       `json:"potential"`
+	LastChange time.Time       `json:"last_change"`
+	State      ActivationState `json:"state"`
+}
+
+func (ps PotentialState) toJSON() potentialStateJSON {
+	return potentialStateJSON{ENCODING_VERSION, ps.last_potential, ps.last_change, ps.state}
+}
+
+func (j potentialStateJSON) potentialState() (PotentialState, error) {
+	if err := checkVersion(j.Version); err != nil {
+		return PotentialState{}, err
+	}
+	return PotentialState{j.Potential, j.LastChange, j.State}, nil
+}
+
+// MarshalJSON encodes the potential state as a JSON object.
+func (ps PotentialState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ps.toJSON())
+}
+
+// UnmarshalJSON restores a potential state encoded by MarshalJSON.
+func (ps *PotentialState) UnmarshalJSON(data []byte) error {
+	var j potentialStateJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	decoded, err := j.potentialState()
+	if err != nil {
+		return err
+	}
+	*ps = decoded
+	return nil
+}
+
+type simpleParamsJSON struct {
+	Threshold        Potential `json:"threshold"`
+	Peak             Potential `json:"peak"`
+	Refractory       Potential `json:"refractory"`
+	DecayDuration    string    `json:"decay_duration"`
+	ActiveDuration   string    `json:"active_duration"`
+	InactiveDuration string    `json:"inactive_duration"`
+}
+
+// MarshalJSON encodes the params as a JSON object, with durations
+// such as "3ms".
+func (p SimpleParams) MarshalJSON() ([]byte, error) {
+	return json.Marshal(simpleParamsJSON{
+		p.Threshold,
+		p.Peak,
+		p.Refractory,
+		p.DecayDuration.String(),
+		p.ActiveDuration.String(),
+		p.InactiveDuration.String(),
+	})
+}
+
+// UnmarshalJSON decodes params encoded by MarshalJSON. The params
+// are not validated.
+func (p *SimpleParams) UnmarshalJSON(data []byte) error {
+	var j simpleParamsJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	decoded := SimpleParams{
+		Threshold:  j.Threshold,
+		Peak:       j.Peak,
+		Refractory: j.Refractory,
+	}
+	durations := []struct {
+		text     string
+		duration *time.Duration
+	}{
+		{j.DecayDuration, &decoded.DecayDuration},
+		{j.ActiveDuration, &decoded.ActiveDuration},
+		{j.InactiveDuration, &decoded.InactiveDuration},
+	}
+	for _, d := range durations {
+		var err error
+		if *d.duration, err = time.ParseDuration(d.text); err != nil {
+			return err
+		}
+	}
+	*p = decoded
+	return nil
+}
+
+// MarshalBinary encodes the potential state and params of the Simple,
+// so that it can be restored with UnmarshalBinary.
+func (cb Simple) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(ENCODING_VERSION)
+	if err := writePotentialState(&buf, cb.PotentialState); err != nil {
+		return nil, err
+	}
+	if cb.params == nil {
+		buf.WriteByte(0)
+	} else {
+		buf.WriteByte(1)
+		binary.Write(&buf, binary.BigEndian, cb.params)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores a Simple encoded by MarshalBinary,
+// returning an error if its params are not valid.
+func (cb *Simple) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if err := readVersion(r); err != nil {
+		return err
+	}
+	ps, err := readPotentialState(r)
+	if err != nil {
+		return err
+	}
+	has_params, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	var params *SimpleParams
+	if has_params != 0 {
+		params = new(SimpleParams)
+		if err := binary.Read(r, binary.BigEndian, params); err != nil {
+			return err
+		}
+		if err := params.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := checkFullyRead(r); err != nil {
+		return err
+	}
+	cb.PotentialState = ps
+	cb.params = params
+	return nil
+}
+
+type simpleJSON struct {
+	potentialStateJSON
+	Params *SimpleParams `json:"params,omitempty"`
+}
+
+// MarshalJSON encodes the potential state and params of the Simple
+// as a JSON object. The params are omitted for a Simple using the
+// defaults.
+func (cb Simple) MarshalJSON() ([]byte, error) {
+	return json.Marshal(simpleJSON{cb.PotentialState.toJSON(), cb.params})
+}
+
+// UnmarshalJSON restores a Simple encoded by MarshalJSON, returning
+// an error if its params are not valid.
+func (cb *Simple) UnmarshalJSON(data []byte) error {
+	var j simpleJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	ps, err := j.potentialState()
+	if err != nil {
+		return err
+	}
+	if j.Params != nil {
+		if err := j.Params.Validate(); err != nil {
+			return err
+		}
+	}
+	cb.PotentialState = ps
+	cb.params = j.Params
+	return nil
+}
+
+// MarshalBinary encodes the potential state and time constant of the
+// LeakyIntegrateAndFire, rather than only the embedded potential state.
+func (lif LeakyIntegrateAndFire) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(ENCODING_VERSION)
+	if err := writePotentialState(&buf, lif.PotentialState); err != nil {
+		return nil, err
+	}
+	binary.Write(&buf, binary.BigEndian, lif.TimeConstant)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores a LeakyIntegrateAndFire encoded by
+// MarshalBinary.
+func (lif *LeakyIntegrateAndFire) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if err := readVersion(r); err != nil {
+		return err
+	}
+	ps, err := readPotentialState(r)
+	if err != nil {
+		return err
+	}
+	var time_constant time.Duration
+	if err := binary.Read(r, binary.BigEndian, &time_constant); err != nil {
+		return err
+	}
+	if err := checkFullyRead(r); err != nil {
+		return err
+	}
+	lif.PotentialState = ps
+	lif.TimeConstant = time_constant
+	return nil
+}
+
+type leakyIntegrateAndFireJSON struct {
+	potentialStateJSON
+	TimeConstant string `json:"time_constant"`
+}
+
+// MarshalJSON encodes the potential state and time constant of the
+// LeakyIntegrateAndFire as a JSON object.
+func (lif LeakyIntegrateAndFire) MarshalJSON() ([]byte, error) {
+	return json.Marshal(leakyIntegrateAndFireJSON{
+		lif.PotentialState.toJSON(),
+		lif.TimeConstant.String(),
+	})
+}
+
+// UnmarshalJSON restores a LeakyIntegrateAndFire encoded by
+// MarshalJSON.
+func (lif *LeakyIntegrateAndFire) UnmarshalJSON(data []byte) error {
+	var j leakyIntegrateAndFireJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	ps, err := j.potentialState()
+	if err != nil {
+		return err
+	}
+	time_constant, err := time.ParseDuration(j.TimeConstant)
+	if err != nil {
+		return err
+	}
+	lif.PotentialState = ps
+	lif.TimeConstant = time_constant
+	return nil
+}
@@ -0,0 +1,192 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+var encoding_cases = []PotentialState{
+	{},
+	{1.5, now, DEACTIVATED},
+	{PEAK_POTENTIAL, now, ACTIVATED},
+	{REFRACTORY_POTENTIAL, now.Add(time.Millisecond), INACTIVATED},
+}
+
+func TestPotentialStateBinaryRoundTrip(t *testing.T) {
+	for i, ps := range encoding_cases {
+		data, err := ps.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%d: Unexpected error: %s", i, err)
+		}
+		var decoded PotentialState
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%d: Unexpected error: %s", i, err)
+		}
+
+		verifyDecoded(t, i, ps, decoded)
+	}
+}
+
+func TestPotentialStateJSONRoundTrip(t *testing.T) {
+	for i, ps := range encoding_cases {
+		data, err := json.Marshal(ps)
+		if err != nil {
+			t.Fatalf("%d: Unexpected error: %s", i, err)
+		}
+		var decoded PotentialState
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%d: Unexpected error: %s", i, err)
+		}
+
+		verifyDecoded(t, i, ps, decoded)
+	}
+}
+
+// verifyDecoded compares potential states ignoring the monotonic
+// clock reading, which is not encoded.
+func verifyDecoded(t *testing.T, testnum int, expected, actual PotentialState) {
+	if expected.last_potential != actual.last_potential ||
+		!expected.last_change.Equal(actual.last_change) ||
+		expected.state != actual.state {
+		t.Errorf("%d. Expected: %s, actual: %s", testnum, expected, actual)
+	}
+}
+
+func TestPotentialStateUnsupportedVersion(t *testing.T) {
+	data, _ := PotentialState{}.MarshalBinary()
+	data[0] = ENCODING_VERSION + 1
+	var ps PotentialState
+
+	if err := ps.UnmarshalBinary(data); err == nil {
+		t.Error("Expected an error for an unsupported binary version.")
+	}
+	if err := ps.UnmarshalBinary(data[:3]); err == nil {
+		t.Error("Expected an error for truncated binary data.")
+	}
+	err := json.Unmarshal([]byte(`{"version":2,"state":"Activated"}`), &ps)
+	if err == nil {
+		t.Error("Expected an error for an unsupported JSON version.")
+	}
+	err = json.Unmarshal([]byte(`{"version":1,"state":"Excited"}`), &ps)
+	if err == nil {
+		t.Error("Expected an error for an unknown state.")
+	}
+}
+
+func TestSimpleResumesFromSnapshot(t *testing.T) {
+	params := DEFAULT_SIMPLE_PARAMS
+	params.Threshold = 5
+	with_params, _ := NewSimple(params)
+	simples := []*Simple{new(Simple), with_params}
+	encodings := []struct {
+		name      string
+		marshal   func(*Simple) ([]byte, error)
+		unmarshal func(*Simple, []byte) error
+	}{
+		{"binary",
+			func(cb *Simple) ([]byte, error) { return cb.MarshalBinary() },
+			func(cb *Simple, data []byte) error { return cb.UnmarshalBinary(data) }},
+		{"json",
+			func(cb *Simple) ([]byte, error) { return json.Marshal(cb) },
+			func(cb *Simple, data []byte) error { return json.Unmarshal(data, cb) }},
+	}
+
+	for _, encoding := range encodings {
+		for i, cb := range simples {
+			cb.AddPotentialAt(3, now)
+			data, err := encoding.marshal(cb)
+			if err != nil {
+				t.Fatalf("%s %d: Unexpected error: %s", encoding.name, i, err)
+			}
+			restored := new(Simple)
+			if err := encoding.unmarshal(restored, data); err != nil {
+				t.Fatalf("%s %d: Unexpected error: %s", encoding.name, i, err)
+			}
+
+			expected_potential, expected_fired := cb.AddPotentialAt(3, now.Add(time.Millisecond))
+			actual_potential, fired := restored.AddPotentialAt(3, now.Add(time.Millisecond))
+
+			if restored.Params() != cb.Params() {
+				t.Errorf("%s %d: Expected params %+v, actual %+v.",
+					encoding.name, i, cb.Params(), restored.Params())
+			}
+			if actual_potential != expected_potential || fired != expected_fired {
+				t.Errorf("%s %d: Expected %.1f (fired: %t), actual %.1f (fired: %t).",
+					encoding.name, i, expected_potential, expected_fired,
+					actual_potential, fired)
+			}
+		}
+	}
+}
+
+func TestSimpleRejectsInvalidParams(t *testing.T) {
+	data := []byte(`{"version":1,"state":"Deactivated","params":{` +
+		`"threshold":-1,"peak":100,"refractory":-15,"decay_duration":"3ms",` +
+		`"active_duration":"3ms","inactive_duration":"3ms"}}`)
+	cb := new(Simple)
+
+	if err := json.Unmarshal(data, cb); err == nil {
+		t.Error("Expected an error for invalid params.")
+	}
+}
+
+func TestLeakyIntegrateAndFireSnapshot(t *testing.T) {
+	lif := NewLeakyIntegrateAndFire(20 * time.Millisecond)
+	lif.AddPotentialAt(10, now)
+
+	data, err := json.Marshal(lif)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	restored := new(LeakyIntegrateAndFire)
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	data, err = lif.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	restored_binary := new(LeakyIntegrateAndFire)
+	if err := restored_binary.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	at := now.Add(5 * time.Millisecond)
+	expected_potential := lif.GetPotentialAt(at)
+	for _, r := range []*LeakyIntegrateAndFire{restored, restored_binary} {
+		if r.TimeConstant != lif.TimeConstant {
+			t.Errorf("Expected time constant %s, actual %s.",
+				lif.TimeConstant, r.TimeConstant)
+		}
+		if actual_potential := r.GetPotentialAt(at); actual_potential != expected_potential {
+			t.Errorf("Expected potential %.3f, actual %.3f.",
+				expected_potential, actual_potential)
+		}
+	}
+}
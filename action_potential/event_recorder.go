@@ -26,31 +26,119 @@
 package action_potential
 
 import (
+	"fmt"
+	"github.com/absoludity/go-neuron/clock"
 	"time"
 )
 
+// An AddPotentialEvent records potential added to an action
+// potential, together with the resulting potential and whether it
+// fired.
 type AddPotentialEvent struct {
 	Potential Potential
 	Time      time.Time
 	RealTime  time.Time
+	Result    Potential
+	Fired     bool
+}
+
+// An EventSink receives each event as it is recorded. An EventWriter
+// is an EventSink.
+type EventSink interface {
+	Write(AddPotentialEvent) error
+}
+
+// A ChannelSink sends each event to a channel, blocking until it is
+// received.
+type ChannelSink chan<- AddPotentialEvent
+
+func (c ChannelSink) Write(e AddPotentialEvent) error {
+	c <- e
+	return nil
 }
 
 // An EventRecorder encapsulates an action potential and records
 // each AddPotential event that occurs.
+//
+// By default every event is kept in Events. A bounded recorder keeps
+// only the most recent events in a ring buffer, and a recorder with a
+// sink forwards each event to it as it happens.
 type EventRecorder struct {
 	ActionPotential
+	// Events are the recorded events. For a bounded recorder this is
+	// a ring buffer, so use Recorded for the events in order.
 	Events []AddPotentialEvent
+	// Err is the first error returned by the sink, if any.
+	Err error
+	// Clock provides the time for AddPotential and the RealTime
+	// of events. The nil value uses the system clock.
+	Clock clock.Clock
+
+	bounded  bool
+	capacity int
+	next     int
+	sink     EventSink
 }
 
 func NewEventRecorder(ap ActionPotential) *EventRecorder {
-	return &EventRecorder{ap, make([]AddPotentialEvent, 0, 10)}
+	return &EventRecorder{ActionPotential: ap, Events: make([]AddPotentialEvent, 0, 10)}
+}
+
+// NewBoundedEventRecorder returns an EventRecorder which keeps only
+// the most recent capacity events, none if the capacity is zero, or an
+// error if the capacity is negative.
+func NewBoundedEventRecorder(ap ActionPotential, capacity int) (*EventRecorder, error) {
+	if capacity < 0 {
+		return nil, fmt.Errorf("event recorder capacity %d must not be negative", capacity)
+	}
+	return &EventRecorder{
+		ActionPotential: ap,
+		Events:          make([]AddPotentialEvent, 0, capacity),
+		bounded:         true,
+		capacity:        capacity,
+	}, nil
+}
+
+// NewStreamingEventRecorder returns an EventRecorder which keeps no
+// events itself, but writes each one to the sink as it happens.
+func NewStreamingEventRecorder(ap ActionPotential, sink EventSink) *EventRecorder {
+	return &EventRecorder{ActionPotential: ap, bounded: true, sink: sink}
+}
+
+func (f *EventRecorder) record(e AddPotentialEvent) {
+	if f.sink != nil {
+		if err := f.sink.Write(e); err != nil && f.Err == nil {
+			f.Err = err
+		}
+	}
+	switch {
+	case !f.bounded:
+		f.Events = append(f.Events, e)
+	case f.capacity == 0:
+		// Nothing is kept.
+	case len(f.Events) < f.capacity:
+		f.Events = append(f.Events, e)
+	default:
+		f.Events[f.next] = e
+		f.next = (f.next + 1) % f.capacity
+	}
+}
+
+// Recorded returns the kept events in the order they were recorded.
+func (f *EventRecorder) Recorded() []AddPotentialEvent {
+	if f.next == 0 {
+		return f.Events
+	}
+	return append(append([]AddPotentialEvent{}, f.Events[f.next:]...), f.Events[:f.next]...)
 }
 
 func (f *EventRecorder) AddPotentialAt(p Potential, t time.Time) (Potential, bool) {
-	f.Events = append(f.Events, AddPotentialEvent{p, t, time.Now()})
-	return f.ActionPotential.AddPotentialAt(p, t)
+	real_time := clock.OrSystem(f.Clock).Now()
+	potential, fired := f.ActionPotential.AddPotentialAt(p, t)
+	f.record(AddPotentialEvent{p, t, real_time, potential, fired})
+	return potential, fired
 }
 
 func (f *EventRecorder) AddPotential(p Potential) (Potential, bool) {
-	return f.AddPotentialAt(p, time.Now())
+	return f.AddPotentialAt(p, clock.OrSystem(f.Clock).Now())
 }
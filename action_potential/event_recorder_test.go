@@ -26,6 +26,8 @@
 package action_potential
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 	"time"
 )
@@ -109,3 +111,108 @@ func TestEventRecorderAddPotential(t *testing.T) {
 		t.Errorf("Expected return value: 6.0, actual %.1f.", actual_potential)
 	}
 }
+
+func TestEventRecorderRecordsOutcome(t *testing.T) {
+	fake := NewEventRecorder(new(Simple))
+
+	fake.AddPotentialAt(10, now)
+	fake.AddPotentialAt(10, now.Add(time.Microsecond))
+
+	if fake.Events[0].Result != 10 || fake.Events[0].Fired {
+		t.Errorf("Expected first event to result in 10.0 without firing, "+
+			"but was %v.", fake.Events[0])
+	}
+	if fake.Events[1].Result != PEAK_POTENTIAL || !fake.Events[1].Fired {
+		t.Errorf("Expected second event to fire, but was %v.", fake.Events[1])
+	}
+}
+
+func TestBoundedEventRecorder(t *testing.T) {
+	fake, err := NewBoundedEventRecorder(new(Simple), 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		fake.AddPotentialAt(Potential(i), now.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	if len(fake.Events) != 3 {
+		t.Errorf("Expected %d events, received %d.", 3, len(fake.Events))
+	}
+	recorded := fake.Recorded()
+	for i, e := range recorded {
+		if e.Potential != Potential(i+2) {
+			t.Errorf("Expected event %d potential to be %d, but was %.1f.",
+				i, i+2, e.Potential)
+		}
+	}
+}
+
+func TestBoundedEventRecorderCapacity(t *testing.T) {
+	if fake, err := NewBoundedEventRecorder(new(Simple), -1); err == nil || fake != nil {
+		t.Errorf("Expected an error for a negative capacity.")
+	}
+
+	fake, err := NewBoundedEventRecorder(new(Simple), 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	fake.AddPotentialAt(10, now)
+	if len(fake.Recorded()) != 0 {
+		t.Errorf("Expected no events to be kept, but kept %d.", len(fake.Recorded()))
+	}
+}
+
+func TestStreamingEventRecorderWriter(t *testing.T) {
+	var buf bytes.Buffer
+	fake := NewStreamingEventRecorder(new(Simple), NewEventWriter(&buf))
+
+	fake.AddPotentialAt(10, now)
+	fake.AddPotentialAt(10, now.Add(time.Microsecond))
+
+	if len(fake.Events) != 0 {
+		t.Errorf("Expected no events to be kept, but kept %d.", len(fake.Events))
+	}
+	events, err := ReadEvents(&buf)
+	if err != nil || fake.Err != nil {
+		t.Fatalf("Unexpected errors: %v, %v", err, fake.Err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 streamed events, received %d.", len(events))
+	}
+	if !events[1].Fired || events[1].Result != PEAK_POTENTIAL {
+		t.Errorf("Expected streamed event to record firing, but was %v.", events[1])
+	}
+}
+
+func TestStreamingEventRecorderChannel(t *testing.T) {
+	ch := make(chan AddPotentialEvent, 1)
+	fake := NewStreamingEventRecorder(new(Simple), ChannelSink(ch))
+
+	fake.AddPotentialAt(1, now)
+
+	e := <-ch
+	if e.Potential != 1 || e.Time != now {
+		t.Errorf("Expected event for 1.0 at %s, but was %v.", now, e)
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) Write(AddPotentialEvent) error {
+	return errors.New("disk full")
+}
+
+func TestStreamingEventRecorderError(t *testing.T) {
+	fake := NewStreamingEventRecorder(new(Simple), failingSink{})
+
+	_, fired := fake.AddPotentialAt(20, now)
+
+	if !fired {
+		t.Error("Expected the action potential to fire despite the sink error.")
+	}
+	if fake.Err == nil {
+		t.Error("Expected the sink error to be recorded.")
+	}
+}
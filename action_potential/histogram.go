@@ -0,0 +1,115 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// A DurationHistogram counts durations, positive or negative, in
+// buckets whose width grows with the magnitude of the duration so that
+// any duration is counted within about 6% of its value, using a fixed
+// amount of memory.
+type DurationHistogram struct {
+	// The counts of negative and non-negative durations by the bucket
+	// of their magnitude.
+	negative, positive [histogram_buckets]int64
+	count              int64
+}
+
+// Magnitudes below 32ns have a bucket each, after which each power of
+// two is split into 16 buckets.
+const (
+	histogram_sub_buckets = 16
+	histogram_buckets     = (64-5)*histogram_sub_buckets + 2*histogram_sub_buckets
+)
+
+func bucketOf(magnitude uint64) int {
+	length := bits.Len64(magnitude)
+	if length <= 5 {
+		return int(magnitude)
+	}
+	shift := uint(length - 5)
+	return int(shift)*histogram_sub_buckets + int(magnitude>>shift)
+}
+
+// bucketRange returns the smallest magnitude in the bucket and the
+// smallest magnitude of the next bucket.
+func bucketRange(bucket int) (uint64, uint64) {
+	if bucket < 2*histogram_sub_buckets {
+		return uint64(bucket), uint64(bucket + 1)
+	}
+	shift := uint(bucket/histogram_sub_buckets - 1)
+	sub := uint64(bucket%histogram_sub_buckets + histogram_sub_buckets)
+	return sub << shift, (sub + 1) << shift
+}
+
+// Add counts the duration.
+func (h *DurationHistogram) Add(d time.Duration) {
+	if d < 0 {
+		h.negative[bucketOf(uint64(-d))] += 1
+	} else {
+		h.positive[bucketOf(uint64(d))] += 1
+	}
+	h.count += 1
+}
+
+// Count returns the number of durations counted.
+func (h *DurationHistogram) Count() int64 {
+	return h.count
+}
+
+// Percentile returns an estimate of the duration below which the
+// given percentage (0-100) of the counted durations fall, or zero if
+// none have been counted.
+func (h *DurationHistogram) Percentile(percent float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(percent / 100 * float64(h.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	seen := int64(0)
+	for b := histogram_buckets - 1; b >= 0; b-- {
+		if seen += h.negative[b]; seen >= rank {
+			return -midpoint(b)
+		}
+	}
+	for b := 0; b < histogram_buckets; b++ {
+		if seen += h.positive[b]; seen >= rank {
+			return midpoint(b)
+		}
+	}
+	return midpoint(histogram_buckets - 1)
+}
+
+func midpoint(bucket int) time.Duration {
+	low, high := bucketRange(bucket)
+	return time.Duration(low + (high-1-low)/2)
+}
@@ -0,0 +1,82 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationHistogramBuckets(t *testing.T) {
+	magnitudes := []uint64{0, 1, 31, 32, 33, 1000, 123456789, 1 << 62, 1 << 63}
+
+	for _, m := range magnitudes {
+		low, high := bucketRange(bucketOf(m))
+
+		if m < low || m >= high {
+			t.Errorf("Expected %d in bucket range [%d,%d).", m, low, high)
+		}
+		if m >= 32 && high-low > low/16 {
+			t.Errorf("Expected bucket [%d,%d) to be within 1/16 of %d.",
+				low, high, m)
+		}
+	}
+}
+
+func TestDurationHistogramPercentile(t *testing.T) {
+	var h DurationHistogram
+	if h.Percentile(50) != 0 {
+		t.Errorf("Expected zero percentile when empty, got %s.", h.Percentile(50))
+	}
+	for i := -50; i < 50; i++ {
+		h.Add(time.Duration(i) * time.Millisecond)
+	}
+
+	cases := []struct {
+		percent  float64
+		expected time.Duration
+	}{
+		{1, -50 * time.Millisecond},
+		{50, -1 * time.Millisecond},
+		{51, 0},
+		{90, 39 * time.Millisecond},
+		{100, 49 * time.Millisecond},
+	}
+	for _, tt := range cases {
+		actual := h.Percentile(tt.percent)
+		slop := tt.expected / 16
+		if slop < 0 {
+			slop = -slop
+		}
+		if actual < tt.expected-slop || actual > tt.expected+slop {
+			t.Errorf("Expected %.0fth percentile %s, actual %s.",
+				tt.percent, tt.expected, actual)
+		}
+	}
+	if h.Count() != 100 {
+		t.Errorf("Expected Count=100, actual %d.", h.Count())
+	}
+}
@@ -0,0 +1,227 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"errors"
+	"github.com/absoludity/go-neuron/clock"
+	"sort"
+	"time"
+)
+
+// ErrTooLate is returned when potential is added at a time which a
+// History can no longer, or may not, re-evaluate.
+var ErrTooLate = errors.New("potential added too late to be re-evaluated")
+
+// A LatePolicy determines how a History handles potential added at a
+// time before its most recent input.
+type LatePolicy int
+
+const (
+	// REEVALUATE replays the retained inputs in time order, including
+	// the late one, provided it is within the window.
+	REEVALUATE LatePolicy = iota
+	// REJECT reports any out-of-order input as too late.
+	REJECT
+)
+
+// The default duration for which a History retains its inputs.
+const HISTORY_WINDOW = 10 * time.Millisecond
+
+type historyInput struct {
+	potential Potential
+	time      time.Time
+	// fired records whether the input has been reported as firing.
+	fired bool
+}
+
+// A History is a Simple which tolerates potential added out of time
+// order. It retains the inputs within a window before its most recent
+// input, together with a checkpoint of the state before the oldest
+// of them, so that a late input can be inserted in order and the
+// state re-evaluated. Inputs older than the window are folded into the
+// checkpoint and forgotten, so the memory used is bounded by the rate
+// of input over the window.
+//
+// When a late input causes a later retained input to fire, the late
+// input reports the firing, and FiredAt returns the time of the input
+// which fired. Firings already reported for later inputs are not
+// retracted when a late input changes them.
+type History struct {
+	// Window is how far before the most recent input a late input
+	// may arrive. The zero value uses HISTORY_WINDOW.
+	Window time.Duration
+	Policy LatePolicy
+	// TooLate counts the inputs rejected with ErrTooLate.
+	TooLate int64
+	// Clock provides the time for GetPotential and AddPotential.
+	// The nil value uses the system clock.
+	Clock clock.Clock
+
+	checkpoint Simple
+	current    Simple
+	inputs     []historyInput
+	fired_at   time.Time
+}
+
+// NewHistory returns a History of a Simple with the given params, or
+// an error if the params are not valid.
+func NewHistory(params SimpleParams, window time.Duration, policy LatePolicy) (*History, error) {
+	cb, err := NewSimple(params)
+	if err != nil {
+		return nil, err
+	}
+	return &History{
+		Window:     window,
+		Policy:     policy,
+		checkpoint: *cb,
+		current:    *cb,
+	}, nil
+}
+
+func (h *History) window() time.Duration {
+	if h.Window <= 0 {
+		return HISTORY_WINDOW
+	}
+	return h.Window
+}
+
+// latest returns the time of the most recent input.
+func (h *History) latest() time.Time {
+	return h.inputs[len(h.inputs)-1].time
+}
+
+// forget folds the inputs which are older than the window into the
+// checkpoint.
+func (h *History) forget() {
+	horizon := h.latest().Add(-h.window())
+	i := 0
+	for ; i < len(h.inputs) && h.inputs[i].time.Before(horizon); i++ {
+		h.checkpoint.AddPotentialAt(h.inputs[i].potential, h.inputs[i].time)
+	}
+	h.inputs = append(h.inputs[:0], h.inputs[i:]...)
+}
+
+// replay re-evaluates the state from the checkpoint for each retained
+// input, returning the potential for the input at the given index and
+// whether any input which had not fired before now does, recording the
+// time of the earliest.
+func (h *History) replay(index int) (Potential, bool) {
+	h.current = h.checkpoint
+	var (
+		potential Potential
+		fired     bool
+	)
+	for i := range h.inputs {
+		in := &h.inputs[i]
+		p, f := h.current.AddPotentialAt(in.potential, in.time)
+		if i == index {
+			potential = p
+		}
+		if f && !in.fired {
+			in.fired = true
+			if !fired {
+				fired, h.fired_at = true, in.time
+			}
+		}
+	}
+	return potential, fired
+}
+
+// FiredAt returns the time of the firing most recently reported, which
+// for a late input may be that of a later input which it caused to
+// fire.
+func (h *History) FiredAt() time.Time {
+	return h.fired_at
+}
+
+// TryAddPotentialAt adds the specified potential at the specified
+// time, re-evaluating the state if the time is before the most recent
+// input, in which case it reports having fired if any retained input
+// newly fires. It returns ErrTooLate, without changing the state, if
+// the time is before the window or the policy is REJECT.
+func (h *History) TryAddPotentialAt(potential Potential, t time.Time) (Potential, bool, error) {
+	in := historyInput{potential: potential, time: t}
+	if len(h.inputs) == 0 || !t.Before(h.latest()) {
+		p, fired := h.current.AddPotentialAt(potential, t)
+		if fired {
+			in.fired, h.fired_at = true, t
+		}
+		h.inputs = append(h.inputs, in)
+		h.forget()
+		return p, fired, nil
+	}
+
+	if h.Policy == REJECT || t.Before(h.latest().Add(-h.window())) {
+		h.TooLate += 1
+		return h.GetPotentialAt(t), false, ErrTooLate
+	}
+	// Insert after any inputs at the same time, so that inputs
+	// at equal times are applied in the order they arrived.
+	index := sort.Search(len(h.inputs), func(i int) bool {
+		return h.inputs[i].time.After(t)
+	})
+	h.inputs = append(h.inputs, historyInput{})
+	copy(h.inputs[index+1:], h.inputs[index:])
+	h.inputs[index] = in
+	p, fired := h.replay(index)
+	return p, fired, nil
+}
+
+// AddPotentialAt adds the specified potential at the specified time,
+// as for TryAddPotentialAt, ignoring any error.
+func (h *History) AddPotentialAt(potential Potential, t time.Time) (Potential, bool) {
+	p, fired, _ := h.TryAddPotentialAt(potential, t)
+	return p, fired
+}
+
+// AddPotential adds the specified potential at the time it is called.
+func (h *History) AddPotential(potential Potential) (Potential, bool) {
+	return h.AddPotentialAt(potential, clock.OrSystem(h.Clock).Now())
+}
+
+// GetPotentialAt determines the potential at the given time without
+// changing the state, replaying the retained inputs if the time is
+// before the most recent one.
+func (h *History) GetPotentialAt(t time.Time) Potential {
+	if len(h.inputs) == 0 || !t.Before(h.latest()) {
+		current := h.current
+		return current.GetPotentialAt(t)
+	}
+	past := h.checkpoint
+	for _, in := range h.inputs {
+		if in.time.After(t) {
+			break
+		}
+		past.AddPotentialAt(in.potential, in.time)
+	}
+	return past.GetPotentialAt(t)
+}
+
+// GetPotential determines the potential at the time it is called.
+func (h *History) GetPotential() Potential {
+	return h.GetPotentialAt(clock.OrSystem(h.Clock).Now())
+}
@@ -0,0 +1,169 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"testing"
+	"time"
+)
+
+func newHistory(t *testing.T, policy LatePolicy) *History {
+	h, err := NewHistory(DEFAULT_SIMPLE_PARAMS, 5*time.Millisecond, policy)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return h
+}
+
+func TestHistoryInOrder(t *testing.T) {
+	h := newHistory(t, REEVALUATE)
+	cb := new(Simple)
+	times := []time.Time{
+		now,
+		now.Add(time.Millisecond),
+		now.Add(2 * time.Millisecond),
+		now.Add(10 * time.Millisecond),
+	}
+
+	for i, at := range times {
+		expected_potential, expected_fired := cb.AddPotentialAt(6, at)
+		actual_potential, fired, err := h.TryAddPotentialAt(6, at)
+
+		if err != nil {
+			t.Errorf("%d: Unexpected error: %s", i, err)
+		}
+		if actual_potential != expected_potential || fired != expected_fired {
+			t.Errorf("%d: Expected %.1f (fired: %t), actual %.1f (fired: %t).",
+				i, expected_potential, expected_fired, actual_potential, fired)
+		}
+	}
+}
+
+func TestHistoryReevaluatesLateInput(t *testing.T) {
+	h := newHistory(t, REEVALUATE)
+	h.AddPotentialAt(10, now.Add(2*time.Millisecond))
+
+	actual_potential, fired, err := h.TryAddPotentialAt(10, now.Add(time.Millisecond))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// The late input is evaluated at its own time, before the input
+	// which arrived first, and reports that input newly firing.
+	if actual_potential != 10 || !fired {
+		t.Errorf("Expected 10.0 (fired: true), actual %.1f (fired: %t).",
+			actual_potential, fired)
+	}
+	if h.FiredAt() != now.Add(2*time.Millisecond) {
+		t.Errorf("Expected firing at %s, actual %s.",
+			now.Add(2*time.Millisecond), h.FiredAt())
+	}
+	// Re-evaluating both in order fires the action potential.
+	actual_potential = h.GetPotentialAt(now.Add(2 * time.Millisecond))
+	if actual_potential != PEAK_POTENTIAL {
+		t.Errorf("Expected potential %.1f, actual %.1f.",
+			PEAK_POTENTIAL, actual_potential)
+	}
+	// The potential before the late input is unaffected.
+	actual_potential = h.GetPotentialAt(now.Add(time.Millisecond / 2))
+	if actual_potential != REST_POTENTIAL {
+		t.Errorf("Expected potential %.1f, actual %.1f.",
+			REST_POTENTIAL, actual_potential)
+	}
+}
+
+func TestHistoryReportsFiringOnce(t *testing.T) {
+	h := newHistory(t, REEVALUATE)
+	_, fired_first := h.AddPotentialAt(20, now.Add(2*time.Millisecond))
+
+	// Replaying the first input after the late one still fires it,
+	// but its firing was already reported.
+	_, fired_late := h.AddPotentialAt(1, now.Add(time.Millisecond))
+
+	if !fired_first || fired_late {
+		t.Errorf("Expected firing reported once (true, false), actual (%t, %t).",
+			fired_first, fired_late)
+	}
+}
+
+func TestHistoryTooLate(t *testing.T) {
+	h := newHistory(t, REEVALUATE)
+	h.AddPotentialAt(10, now.Add(10*time.Millisecond))
+
+	_, fired, err := h.TryAddPotentialAt(10, now)
+
+	if err != ErrTooLate {
+		t.Errorf("Expected ErrTooLate, actual %v.", err)
+	}
+	if fired {
+		t.Error("Unexpected firing of action potential.")
+	}
+	if h.TooLate != 1 {
+		t.Errorf("Expected TooLate=1, actual %d.", h.TooLate)
+	}
+	actual_potential := h.GetPotentialAt(now.Add(10 * time.Millisecond))
+	if actual_potential != 10 {
+		t.Errorf("Expected potential 10.0, actual %.1f.", actual_potential)
+	}
+}
+
+func TestHistoryRejectPolicy(t *testing.T) {
+	h := newHistory(t, REJECT)
+	h.AddPotentialAt(10, now.Add(2*time.Millisecond))
+
+	_, _, err := h.TryAddPotentialAt(10, now.Add(time.Millisecond))
+
+	if err != ErrTooLate {
+		t.Errorf("Expected ErrTooLate, actual %v.", err)
+	}
+	if h.TooLate != 1 {
+		t.Errorf("Expected TooLate=1, actual %d.", h.TooLate)
+	}
+}
+
+func TestHistoryWindowIsBounded(t *testing.T) {
+	h := newHistory(t, REEVALUATE)
+	cb := new(Simple)
+
+	for i := 0; i < 100; i++ {
+		h.AddPotentialAt(1, now.Add(time.Duration(i)*time.Millisecond))
+		cb.AddPotentialAt(1, now.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	// Only the inputs within the window of the most recent one
+	// are retained.
+	if len(h.inputs) != 6 {
+		t.Errorf("Expected 6 retained inputs, actual %d.", len(h.inputs))
+	}
+	// Folding the older inputs into the checkpoint does not change
+	// the result.
+	expected_potential := cb.GetPotentialAt(now.Add(99 * time.Millisecond))
+	actual_potential := h.GetPotentialAt(now.Add(99 * time.Millisecond))
+	if actual_potential != expected_potential {
+		t.Errorf("Expected potential %.1f, actual %.1f.",
+			expected_potential, actual_potential)
+	}
+}
@@ -0,0 +1,287 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"github.com/absoludity/go-neuron/clock"
+	"math"
+	"time"
+)
+
+// HodgkinHuxleyParams are the membrane capacitance (uF/cm^2), the
+// maximal sodium, potassium and leak conductances (mS/cm^2) and the
+// corresponding reversal potentials (mV).
+type HodgkinHuxleyParams struct {
+	Capacitance float64
+	GNa, GK, GL float64
+	ENa, EK, EL float64
+}
+
+// The original parameters of Hodgkin and Huxley (1952), shifted so
+// that the resting potential is around -65mV.
+var SQUID_GIANT_AXON = HodgkinHuxleyParams{
+	Capacitance: 1,
+	GNa:         120,
+	GK:          36,
+	GL:          0.3,
+	ENa:         50,
+	EK:          -77,
+	EL:          -54.387,
+}
+
+// The initial membrane potential (mV), the potential which must be
+// crossed on the upstroke for a spike to be detected, the step used
+// when integrating between calls, how far ahead each call to NextSpike
+// looks and how long after the last input or spike it stops looking.
+const (
+	HODGKIN_HUXLEY_INITIAL_POTENTIAL Potential = -65
+	HODGKIN_HUXLEY_SPIKE_POTENTIAL   Potential = 0
+	HODGKIN_HUXLEY_STEP                        = 10 * time.Microsecond
+	HODGKIN_HUXLEY_LOOKAHEAD                   = time.Millisecond
+	HODGKIN_HUXLEY_HORIZON                     = 100 * time.Millisecond
+)
+
+// Indices of the state variables: the membrane potential and the
+// m, h and n gating variables.
+const (
+	hh_v = iota
+	hh_m
+	hh_h
+	hh_n
+)
+
+// A HodgkinHuxley is a conductance-based implementation of the action
+// potential interface, with sodium, potassium and leak channels. As
+// with the Izhikevich, potentials are membrane potentials in mV and
+// the model is integrated from the time of the previous call up to
+// the requested time.
+//
+// A spike is detected when the membrane potential crosses
+// HODGKIN_HUXLEY_SPIKE_POTENTIAL on the upstroke, which happens some
+// time after the depolarising input, and is reported by the next call
+// integrating across the crossing. A call reports at most one of the
+// spikes since the previous call, so the model should be woken for
+// each one at the time given by NextSpike, as a Neuron on an
+// activation stream is.
+//
+// http://en.wikipedia.org/wiki/Hodgkin%E2%80%93Huxley_model
+type HodgkinHuxley struct {
+	HodgkinHuxleyParams
+	// Current is a constant input current (uA/cm^2) injected
+	// between calls.
+	Current float64
+	// Integrator advances the model by each step. The zero value
+	// uses a RungeKutta4.
+	Integrator Integrator
+	// Clock provides the time for GetPotential and AddPotential.
+	// The nil value uses the system clock.
+	Clock clock.Clock
+
+	y           []float64
+	last_change time.Time
+	last_spike  time.Time
+	prediction  prediction
+}
+
+// NewHodgkinHuxley returns a HodgkinHuxley at its initial potential,
+// with each gating variable at its steady state.
+func NewHodgkinHuxley(params HodgkinHuxleyParams) *HodgkinHuxley {
+	v := float64(HODGKIN_HUXLEY_INITIAL_POTENTIAL)
+	return &HodgkinHuxley{
+		HodgkinHuxleyParams: params,
+		y: []float64{
+			v,
+			steadyState(alphaM(v), betaM(v)),
+			steadyState(alphaH(v), betaH(v)),
+			steadyState(alphaN(v), betaN(v)),
+		},
+	}
+}
+
+// exprel returns x / (1 - exp(-x)), which tends to 1 as x tends to 0.
+func exprel(x float64) float64 {
+	if math.Abs(x) < 1e-6 {
+		return 1 + x/2
+	}
+	return x / (1 - math.Exp(-x))
+}
+
+func alphaM(v float64) float64 { return exprel((v + 40) / 10) }
+func betaM(v float64) float64  { return 4 * math.Exp(-(v+65)/18) }
+func alphaH(v float64) float64 { return 0.07 * math.Exp(-(v+65)/20) }
+func betaH(v float64) float64  { return 1 / (1 + math.Exp(-(v+35)/10)) }
+func alphaN(v float64) float64 { return 0.1 * exprel((v+55)/10) }
+func betaN(v float64) float64  { return 0.125 * math.Exp(-(v+65)/80) }
+
+func steadyState(alpha, beta float64) float64 {
+	return alpha / (alpha + beta)
+}
+
+// derivative calculates the rate of change (per ms) of the membrane
+// potential and gating variables.
+func (hh *HodgkinHuxley) derivative(y, dydt []float64) {
+	v, m, h, n := y[hh_v], y[hh_m], y[hh_h], y[hh_n]
+	i_na := hh.GNa * m * m * m * h * (v - hh.ENa)
+	i_k := hh.GK * n * n * n * n * (v - hh.EK)
+	i_l := hh.GL * (v - hh.EL)
+	dydt[hh_v] = (hh.Current - i_na - i_k - i_l) / hh.Capacitance
+	dydt[hh_m] = alphaM(v)*(1-m) - betaM(v)*m
+	dydt[hh_h] = alphaH(v)*(1-h) - betaH(v)*h
+	dydt[hh_n] = alphaN(v)*(1-n) - betaN(v)*n
+}
+
+func (hh *HodgkinHuxley) integrator() Integrator {
+	if hh.Integrator == nil {
+		hh.Integrator = new(RungeKutta4)
+	}
+	return hh.Integrator
+}
+
+// crossed returns whether the membrane potential crossed the spike
+// potential on the upstroke when changing from prev to v.
+func crossed(prev, v float64) bool {
+	threshold := float64(HODGKIN_HUXLEY_SPIKE_POTENTIAL)
+	return prev < threshold && v >= threshold
+}
+
+// settled returns whether each of the changes in a step is too small
+// to matter.
+func settled(changes ...float64) bool {
+	for _, change := range changes {
+		if math.Abs(change) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+// integrate advances the model up to the given time, returning
+// whether it spiked on the way. Times before the last change
+// leave the model untouched.
+func (hh *HodgkinHuxley) integrate(now time.Time) bool {
+	if hh.last_change.IsZero() {
+		hh.last_change = now
+		hh.prediction.restart(now)
+		return false
+	}
+	integrator := hh.integrator()
+	spiked := false
+	for hh.last_change.Before(now) {
+		dt := now.Sub(hh.last_change)
+		if dt > HODGKIN_HUXLEY_STEP {
+			dt = HODGKIN_HUXLEY_STEP
+		}
+		prev := hh.y[hh_v]
+		integrator.Step(hh.derivative, hh.y, float64(dt)/float64(time.Millisecond))
+		hh.last_change = hh.last_change.Add(dt)
+		if crossed(prev, hh.y[hh_v]) {
+			spiked = true
+			hh.last_spike = hh.last_change
+		}
+	}
+	return spiked
+}
+
+// NextSpike returns the end of the integration step in which the
+// membrane potential will next cross the spike potential on the
+// upstroke if no potential is added, or the time to ask again if it
+// does not within HODGKIN_HUXLEY_LOOKAHEAD. It returns false if the
+// model settles at rest first, or has not spiked within
+// HODGKIN_HUXLEY_HORIZON of the last input or spike. Adding no
+// potential at that time reports the spike. The prediction is kept
+// until potential is added, so only the first call after each input
+// integrates ahead.
+func (hh *HodgkinHuxley) NextSpike() (time.Time, bool) {
+	if hh.last_change.IsZero() {
+		return time.Time{}, false
+	}
+	if next, ok, cached := hh.prediction.cached(hh.Current, hh.last_change); cached {
+		return next, ok
+	}
+	integrator := hh.integrator()
+	y := append([]float64(nil), hh.y...)
+	prev := make([]float64, len(y))
+	dt := float64(HODGKIN_HUXLEY_STEP) / float64(time.Millisecond)
+	horizon := hh.prediction.horizon(hh.last_spike, HODGKIN_HUXLEY_HORIZON)
+	for t := hh.last_change; t.Before(horizon); {
+		copy(prev, y)
+		integrator.Step(hh.derivative, y, dt)
+		t = t.Add(HODGKIN_HUXLEY_STEP)
+		if crossed(prev[hh_v], y[hh_v]) {
+			return hh.prediction.store(hh.Current, t, true)
+		}
+		if settled(y[hh_v]-prev[hh_v], y[hh_m]-prev[hh_m], y[hh_h]-prev[hh_h], y[hh_n]-prev[hh_n]) {
+			break
+		}
+		if t.Sub(hh.last_change) >= HODGKIN_HUXLEY_LOOKAHEAD {
+			return hh.prediction.store(hh.Current, t, true)
+		}
+	}
+	return hh.prediction.store(hh.Current, time.Time{}, false)
+}
+
+// LastSpike returns the time at which the most recent spike
+// crossed the spike potential.
+func (hh *HodgkinHuxley) LastSpike() time.Time {
+	return hh.last_spike
+}
+
+// GetPotentialAt integrates the model up to the given time and
+// returns the membrane potential.
+func (hh *HodgkinHuxley) GetPotentialAt(now time.Time) Potential {
+	hh.integrate(now)
+	return Potential(hh.y[hh_v])
+}
+
+// GetPotential integrates the model up to the time it is called and
+// returns the membrane potential.
+func (hh *HodgkinHuxley) GetPotential() Potential {
+	return hh.GetPotentialAt(clock.OrSystem(hh.Clock).Now())
+}
+
+// AddPotentialAt integrates the model up to the given time and then
+// depolarises the membrane by the specified potential. It reports
+// having fired if the membrane potential crossed the spike potential
+// on the upstroke since the previous call, including as a direct
+// result of the added potential.
+func (hh *HodgkinHuxley) AddPotentialAt(potential Potential, now time.Time) (Potential, bool) {
+	fired := hh.integrate(now)
+	if potential != 0 {
+		hh.prediction.restart(now)
+	}
+	prev := hh.y[hh_v]
+	hh.y[hh_v] += float64(potential)
+	if crossed(prev, hh.y[hh_v]) {
+		fired = true
+		hh.last_spike = now
+	}
+	return Potential(hh.y[hh_v]), fired
+}
+
+// AddPotential adds the specified potential at the time it is called.
+func (hh *HodgkinHuxley) AddPotential(potential Potential) (Potential, bool) {
+	return hh.AddPotentialAt(potential, clock.OrSystem(hh.Clock).Now())
+}
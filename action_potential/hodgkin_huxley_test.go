@@ -0,0 +1,181 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"testing"
+	"time"
+)
+
+// countFired drives the model with calls every interval for the
+// duration, counting the calls which report firing.
+func countFired(ap ActionPotential, interval, duration time.Duration) int {
+	fired_count := 0
+	for t := time.Duration(0); t <= duration; t += interval {
+		if _, fired := ap.AddPotentialAt(0, now.Add(t)); fired {
+			fired_count += 1
+		}
+	}
+	return fired_count
+}
+
+// follow adds no potential to the model at each time given by
+// NextSpike until the given time, returning the times at which it
+// fired and whether it stopped predicting first.
+func follow(sp Spontaneous, until time.Time) (spikes []time.Time, stopped bool) {
+	for {
+		next, ok := sp.NextSpike()
+		if !ok {
+			return spikes, true
+		}
+		if next.After(until) {
+			return spikes, false
+		}
+		if _, fired := sp.AddPotentialAt(0, next); fired {
+			spikes = append(spikes, next)
+		}
+	}
+}
+
+func TestHodgkinHuxleyRest(t *testing.T) {
+	hh := NewHodgkinHuxley(SQUID_GIANT_AXON)
+
+	fired_count := countFired(hh, time.Millisecond, 50*time.Millisecond)
+
+	if fired_count != 0 {
+		t.Errorf("Unexpected firing of action potential %d times.", fired_count)
+	}
+	actual_potential := hh.GetPotentialAt(now.Add(50 * time.Millisecond))
+	if actual_potential < -66 || actual_potential > -64 {
+		t.Errorf("Expected a resting potential in [-66,-64], actual %.1f.",
+			actual_potential)
+	}
+}
+
+func TestHodgkinHuxleyFiresOncePerSpike(t *testing.T) {
+	integrators := []Integrator{new(Euler), new(RungeKutta4)}
+
+	for _, integrator := range integrators {
+		hh := NewHodgkinHuxley(SQUID_GIANT_AXON)
+		hh.Integrator = integrator
+		hh.GetPotentialAt(now)
+
+		_, fired := hh.AddPotentialAt(20, now)
+		fired_count := countFired(hh, 100*time.Microsecond, 20*time.Millisecond)
+
+		if fired {
+			t.Errorf("%T: Expected the spike on the upstroke, not on input.",
+				integrator)
+		}
+		if fired_count != 1 {
+			t.Errorf("%T: Expected a single spike to be reported once, "+
+				"reported %d times.", integrator, fired_count)
+		}
+		if !hh.LastSpike().After(now) {
+			t.Errorf("%T: Expected a recorded spike after %s, got %s.",
+				integrator, now, hh.LastSpike())
+		}
+	}
+}
+
+func TestHodgkinHuxleyRepetitiveFiring(t *testing.T) {
+	hh := NewHodgkinHuxley(SQUID_GIANT_AXON)
+	hh.Current = 10
+
+	fired_count := countFired(hh, 100*time.Microsecond, 100*time.Millisecond)
+
+	if fired_count < 5 || fired_count > 9 {
+		t.Errorf("Expected between 5 and 9 spikes in 100ms, got %d.",
+			fired_count)
+	}
+}
+
+func TestHodgkinHuxleyNextSpike(t *testing.T) {
+	rest := NewHodgkinHuxley(SQUID_GIANT_AXON)
+	rest.GetPotentialAt(now)
+	if spikes, stopped := follow(rest, now.Add(2*HODGKIN_HUXLEY_HORIZON)); len(spikes) != 0 || !stopped {
+		t.Errorf("Expected no spikes predicted at rest, got %d (stopped %t).", len(spikes), stopped)
+	}
+
+	hh := NewHodgkinHuxley(SQUID_GIANT_AXON)
+	hh.GetPotentialAt(now)
+	hh.AddPotentialAt(20, now)
+	next, ok := hh.NextSpike()
+
+	if !ok || next.Sub(now) < 500*time.Microsecond || next.Sub(now) > 3*time.Millisecond {
+		t.Fatalf("Expected a spike predicted within [0.5ms,3ms], got %s (%t).",
+			next.Sub(now), ok)
+	}
+	if _, fired := hh.AddPotentialAt(0, next.Add(-HODGKIN_HUXLEY_STEP)); fired {
+		t.Errorf("Unexpected firing one step before the predicted spike.")
+	}
+	if _, fired := hh.AddPotentialAt(0, next); !fired {
+		t.Errorf("Expected firing at the predicted spike.")
+	}
+	if hh.LastSpike() != next {
+		t.Errorf("Expected the spike recorded at %s, got %s.", next, hh.LastSpike())
+	}
+}
+
+func TestHodgkinHuxleyNextSpikeRepetitive(t *testing.T) {
+	// Woken at each predicted spike, every spike is reported at the
+	// time of its crossing.
+	hh := NewHodgkinHuxley(SQUID_GIANT_AXON)
+	hh.Current = 10
+	hh.GetPotentialAt(now)
+
+	spikes, _ := follow(hh, now.Add(100*time.Millisecond))
+
+	if len(spikes) < 5 || len(spikes) > 9 {
+		t.Errorf("Expected between 5 and 9 spikes in 100ms, got %d.", len(spikes))
+	}
+}
+
+func TestHodgkinHuxleyNextSpikeLooksAhead(t *testing.T) {
+	// Subthreshold input is predicted no further than the lookahead,
+	// and the prediction is kept until potential is added.
+	hh := NewHodgkinHuxley(SQUID_GIANT_AXON)
+	hh.GetPotentialAt(now)
+	hh.AddPotentialAt(5, now)
+
+	next, ok := hh.NextSpike()
+
+	if !ok || next != now.Add(HODGKIN_HUXLEY_LOOKAHEAD) {
+		t.Errorf("Expected to be asked again at %s, got %s (%t).",
+			HODGKIN_HUXLEY_LOOKAHEAD, next.Sub(now), ok)
+	}
+	hh.AddPotentialAt(0, now.Add(HODGKIN_HUXLEY_LOOKAHEAD/2))
+	if cached, _ := hh.NextSpike(); cached != next {
+		t.Errorf("Expected the prediction %s to be kept, got %s.", next.Sub(now), cached.Sub(now))
+	}
+	hh.AddPotentialAt(1, now.Add(HODGKIN_HUXLEY_LOOKAHEAD/2))
+	if again, _ := hh.NextSpike(); again == next {
+		t.Errorf("Expected a new prediction after input, got %s.", again.Sub(now))
+	}
+	if spikes, stopped := follow(hh, now.Add(2*HODGKIN_HUXLEY_HORIZON)); len(spikes) != 0 || !stopped {
+		t.Errorf("Expected no spikes after subthreshold input, got %d (stopped %t).", len(spikes), stopped)
+	}
+}
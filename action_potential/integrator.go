@@ -0,0 +1,88 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+// A Derivative calculates the rate of change, dydt, of a system of
+// ordinary differential equations in the state y.
+type Derivative func(y, dydt []float64)
+
+// An Integrator advances the state y of a system of ordinary
+// differential equations by the step dt, in place.
+type Integrator interface {
+	Step(f Derivative, y []float64, dt float64)
+}
+
+// Euler is the explicit first-order Euler method.
+type Euler struct {
+	dydt []float64
+}
+
+func (e *Euler) Step(f Derivative, y []float64, dt float64) {
+	e.dydt = resize(e.dydt, len(y))
+	f(y, e.dydt)
+	for i := range y {
+		y[i] += dt * e.dydt[i]
+	}
+}
+
+// RungeKutta4 is the classical fourth-order Runge-Kutta method.
+type RungeKutta4 struct {
+	k1, k2, k3, k4, tmp []float64
+}
+
+func (rk *RungeKutta4) Step(f Derivative, y []float64, dt float64) {
+	n := len(y)
+	rk.k1 = resize(rk.k1, n)
+	rk.k2 = resize(rk.k2, n)
+	rk.k3 = resize(rk.k3, n)
+	rk.k4 = resize(rk.k4, n)
+	rk.tmp = resize(rk.tmp, n)
+
+	f(y, rk.k1)
+	for i := range y {
+		rk.tmp[i] = y[i] + dt/2*rk.k1[i]
+	}
+	f(rk.tmp, rk.k2)
+	for i := range y {
+		rk.tmp[i] = y[i] + dt/2*rk.k2[i]
+	}
+	f(rk.tmp, rk.k3)
+	for i := range y {
+		rk.tmp[i] = y[i] + dt*rk.k3[i]
+	}
+	f(rk.tmp, rk.k4)
+	for i := range y {
+		y[i] += dt / 6 * (rk.k1[i] + 2*rk.k2[i] + 2*rk.k3[i] + rk.k4[i])
+	}
+}
+
+// resize returns a slice of length n, reusing s when it is large enough.
+func resize(s []float64, n int) []float64 {
+	if cap(s) < n {
+		return make([]float64, n)
+	}
+	return s[:n]
+}
@@ -0,0 +1,64 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"math"
+	"testing"
+)
+
+func integrateDecay(integrator Integrator, steps int) float64 {
+	decay := func(y, dydt []float64) {
+		dydt[0] = -y[0]
+	}
+	y := []float64{1}
+	dt := 1 / float64(steps)
+	for i := 0; i < steps; i++ {
+		integrator.Step(decay, y, dt)
+	}
+	return y[0]
+}
+
+func TestIntegrators(t *testing.T) {
+	cases := []struct {
+		name       string
+		integrator Integrator
+		tolerance  float64
+	}{
+		{"Euler", new(Euler), 1e-2},
+		{"RungeKutta4", new(RungeKutta4), 1e-9},
+	}
+	expected := math.Exp(-1)
+
+	for _, tt := range cases {
+		actual := integrateDecay(tt.integrator, 100)
+
+		if math.Abs(actual-expected) > tt.tolerance {
+			t.Errorf("%s: Expected %f within %g, actual %f.",
+				tt.name, expected, tt.tolerance, actual)
+		}
+	}
+}
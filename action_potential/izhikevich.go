@@ -0,0 +1,219 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"github.com/absoludity/go-neuron/clock"
+	"time"
+)
+
+// IzhikevichParams are the dimensionless a, b, c and d parameters
+// of the Izhikevich model: the recovery time scale, the sensitivity
+// of the recovery variable, the after-spike reset potential (mV) and
+// the after-spike increment of the recovery variable.
+type IzhikevichParams struct {
+	A, B, C, D float64
+}
+
+// Presets for the firing patterns described in Izhikevich (2003),
+// "Simple Model of Spiking Neurons".
+var (
+	REGULAR_SPIKING        = IzhikevichParams{0.02, 0.2, -65, 8}
+	INTRINSICALLY_BURSTING = IzhikevichParams{0.02, 0.2, -55, 4}
+	CHATTERING             = IzhikevichParams{0.02, 0.2, -50, 2}
+	FAST_SPIKING           = IzhikevichParams{0.1, 0.2, -65, 2}
+	LOW_THRESHOLD_SPIKING  = IzhikevichParams{0.02, 0.25, -65, 2}
+	THALAMO_CORTICAL       = IzhikevichParams{0.02, 0.25, -65, 0.05}
+	RESONATOR              = IzhikevichParams{0.1, 0.26, -65, 2}
+)
+
+// The membrane potential (mV) at which an Izhikevich neuron spikes
+// and is reset, the initial membrane potential, the step used when
+// integrating between calls, how far ahead each call to NextSpike
+// looks and how long after the last input or spike it stops looking.
+const (
+	IZHIKEVICH_PEAK_POTENTIAL    Potential = 30
+	IZHIKEVICH_INITIAL_POTENTIAL Potential = -65
+	IZHIKEVICH_STEP                        = 250 * time.Microsecond
+	IZHIKEVICH_LOOKAHEAD                   = 10 * time.Millisecond
+	IZHIKEVICH_HORIZON                     = time.Second
+)
+
+// An Izhikevich is an implementation of the action potential
+// interface using the two-variable model of Izhikevich, which
+// reproduces many cortical firing patterns depending on its
+// parameters. Potentials are membrane potentials in mV, so the
+// resting potential is around -65 rather than REST_POTENTIAL.
+//
+// The model is integrated with the Euler method in steps of
+// IZHIKEVICH_STEP from the time of the previous call up to the
+// requested time, so the cost of a call is proportional to the
+// simulated time since the last one. A call reports at most one of
+// the spikes since the previous call, so a neuron driven by Current
+// should be woken for each one at the time given by NextSpike, as a
+// Neuron on an activation stream is.
+//
+// http://www.izhikevich.org/publications/spikes.htm
+type Izhikevich struct {
+	IzhikevichParams
+	// Current is a constant input current injected between calls.
+	Current float64
+	// Clock provides the time for GetPotential and AddPotential.
+	// The nil value uses the system clock.
+	Clock clock.Clock
+
+	v, u        float64
+	last_change time.Time
+	last_spike  time.Time
+	prediction  prediction
+}
+
+func NewIzhikevich(params IzhikevichParams) *Izhikevich {
+	v := float64(IZHIKEVICH_INITIAL_POTENTIAL)
+	return &Izhikevich{IzhikevichParams: params, v: v, u: params.B * v}
+}
+
+// step integrates the model over dt, returning whether the
+// neuron spiked.
+func (iz *Izhikevich) step(dt time.Duration) bool {
+	ms := float64(dt) / float64(time.Millisecond)
+	v, u := iz.v, iz.u
+	iz.v += ms * (0.04*v*v + 5*v + 140 - u + iz.Current)
+	iz.u += ms * iz.A * (iz.B*v - u)
+	if iz.v >= float64(IZHIKEVICH_PEAK_POTENTIAL) {
+		iz.reset()
+		return true
+	}
+	return false
+}
+
+func (iz *Izhikevich) reset() {
+	iz.v = iz.C
+	iz.u += iz.D
+}
+
+// integrate advances the model up to the given time, returning
+// whether it spiked on the way. Times before the last change
+// leave the model untouched.
+func (iz *Izhikevich) integrate(now time.Time) bool {
+	if iz.last_change.IsZero() {
+		iz.last_change = now
+		iz.prediction.restart(now)
+		return false
+	}
+	spiked := false
+	for iz.last_change.Before(now) {
+		dt := now.Sub(iz.last_change)
+		if dt > IZHIKEVICH_STEP {
+			dt = IZHIKEVICH_STEP
+		}
+		iz.last_change = iz.last_change.Add(dt)
+		if iz.step(dt) {
+			spiked = true
+			iz.last_spike = iz.last_change
+		}
+	}
+	return spiked
+}
+
+// NextSpike returns the end of the integration step in which the
+// model will next spike if no potential is added, or the time to ask
+// again if it does not within IZHIKEVICH_LOOKAHEAD. It returns false
+// if the model settles at rest first, or has not spiked within
+// IZHIKEVICH_HORIZON of the last input or spike. Adding no potential
+// at that time reports the spike. The prediction is kept until
+// potential is added, so only the first call after each input
+// integrates ahead.
+func (iz *Izhikevich) NextSpike() (time.Time, bool) {
+	if iz.last_change.IsZero() {
+		return time.Time{}, false
+	}
+	if next, ok, cached := iz.prediction.cached(iz.Current, iz.last_change); cached {
+		return next, ok
+	}
+	ahead := *iz
+	horizon := iz.prediction.horizon(iz.last_spike, IZHIKEVICH_HORIZON)
+	for ahead.last_change.Before(horizon) {
+		v, u := ahead.v, ahead.u
+		ahead.last_change = ahead.last_change.Add(IZHIKEVICH_STEP)
+		if ahead.step(IZHIKEVICH_STEP) {
+			return iz.prediction.store(iz.Current, ahead.last_change, true)
+		}
+		if settled(ahead.v-v, ahead.u-u) {
+			break
+		}
+		if ahead.last_change.Sub(iz.last_change) >= IZHIKEVICH_LOOKAHEAD {
+			return iz.prediction.store(iz.Current, ahead.last_change, true)
+		}
+	}
+	return iz.prediction.store(iz.Current, time.Time{}, false)
+}
+
+// LastSpike returns the time of the most recent spike, including
+// those caused by Current between calls.
+func (iz *Izhikevich) LastSpike() time.Time {
+	return iz.last_spike
+}
+
+// GetPotentialAt integrates the model up to the given time and
+// returns the membrane potential.
+func (iz *Izhikevich) GetPotentialAt(now time.Time) Potential {
+	iz.integrate(now)
+	return Potential(iz.v)
+}
+
+// GetPotential integrates the model up to the time it is called and
+// returns the membrane potential.
+func (iz *Izhikevich) GetPotential() Potential {
+	return iz.GetPotentialAt(clock.OrSystem(iz.Clock).Now())
+}
+
+// AddPotentialAt integrates the model up to the given time and then
+// adds the specified potential to the membrane potential. It reports
+// having fired if the neuron spiked since the previous call, either
+// on the way or because of the added potential, in which case the
+// peak potential is returned.
+func (iz *Izhikevich) AddPotentialAt(potential Potential, now time.Time) (Potential, bool) {
+	fired := iz.integrate(now)
+	if potential != 0 {
+		iz.prediction.restart(now)
+	}
+	iz.v += float64(potential)
+	if iz.v >= float64(IZHIKEVICH_PEAK_POTENTIAL) {
+		iz.reset()
+		iz.last_spike = now
+		fired = true
+	}
+	if fired {
+		return IZHIKEVICH_PEAK_POTENTIAL, fired
+	}
+	return Potential(iz.v), fired
+}
+
+// AddPotential adds the specified potential at the time it is called.
+func (iz *Izhikevich) AddPotential(potential Potential) (Potential, bool) {
+	return iz.AddPotentialAt(potential, clock.OrSystem(iz.Clock).Now())
+}
@@ -0,0 +1,163 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"testing"
+	"time"
+)
+
+// countSpikes drives an Izhikevich neuron with the given constant
+// current for the duration, checking for spikes every millisecond.
+func countSpikes(params IzhikevichParams, current float64, duration time.Duration) int {
+	iz := NewIzhikevich(params)
+	iz.Current = current
+	spikes := 0
+	for t := time.Duration(0); t <= duration; t += time.Millisecond {
+		if _, fired := iz.AddPotentialAt(0, now.Add(t)); fired {
+			spikes += 1
+		}
+	}
+	return spikes
+}
+
+func TestIzhikevichRest(t *testing.T) {
+	iz := NewIzhikevich(REGULAR_SPIKING)
+	iz.GetPotentialAt(now)
+
+	actual_potential, fired := iz.AddPotentialAt(0, now.Add(time.Second))
+
+	if fired {
+		t.Error("Unexpected firing of action potential.")
+	}
+	if actual_potential < -75 || actual_potential > -60 {
+		t.Errorf("Expected a resting potential in [-75,-60], actual %.1f.",
+			actual_potential)
+	}
+}
+
+func TestIzhikevichFiresOnInput(t *testing.T) {
+	iz := NewIzhikevich(REGULAR_SPIKING)
+	iz.GetPotentialAt(now)
+
+	actual_potential, fired := iz.AddPotentialAt(100, now.Add(time.Millisecond))
+
+	if !fired {
+		t.Error("Expected action potential to fire, but didn't.")
+	}
+	if actual_potential != IZHIKEVICH_PEAK_POTENTIAL {
+		t.Errorf("Expected potential %.1f, actual %.1f.",
+			IZHIKEVICH_PEAK_POTENTIAL, actual_potential)
+	}
+	reset := iz.GetPotentialAt(now.Add(time.Millisecond))
+	if reset != Potential(REGULAR_SPIKING.C) {
+		t.Errorf("Expected reset potential %.1f, actual %.1f.",
+			REGULAR_SPIKING.C, reset)
+	}
+	if iz.LastSpike() != now.Add(time.Millisecond) {
+		t.Errorf("Expected last spike at %s, actual %s.",
+			now.Add(time.Millisecond), iz.LastSpike())
+	}
+}
+
+func TestIzhikevichIntegratesBetweenCalls(t *testing.T) {
+	iz := NewIzhikevich(REGULAR_SPIKING)
+	iz.Current = 10
+	iz.GetPotentialAt(now)
+
+	iz.GetPotentialAt(now.Add(time.Second))
+
+	if !iz.LastSpike().After(now) {
+		t.Error("Expected the constant current to cause a spike.")
+	}
+}
+
+func TestIzhikevichPresets(t *testing.T) {
+	regular := countSpikes(REGULAR_SPIKING, 10, time.Second)
+	fast := countSpikes(FAST_SPIKING, 10, time.Second)
+
+	if regular == 0 {
+		t.Error("Expected regular spiking neuron to fire.")
+	}
+	if fast <= regular {
+		t.Errorf("Expected fast spiking (%d spikes) to fire more often "+
+			"than regular spiking (%d spikes).", fast, regular)
+	}
+}
+
+func TestIzhikevichNextSpike(t *testing.T) {
+	rest := NewIzhikevich(REGULAR_SPIKING)
+	rest.GetPotentialAt(now)
+	if spikes, stopped := follow(rest, now.Add(2*IZHIKEVICH_HORIZON)); len(spikes) != 0 || !stopped {
+		t.Errorf("Expected no spikes predicted at rest, got %d (stopped %t).", len(spikes), stopped)
+	}
+
+	iz := NewIzhikevich(REGULAR_SPIKING)
+	iz.GetPotentialAt(now)
+	_, fired := iz.AddPotentialAt(40, now)
+	next, ok := iz.NextSpike()
+
+	if fired {
+		t.Errorf("Expected the spike after the input, not on input.")
+	}
+	if !ok || !next.After(now) || next.Sub(now) > 5*time.Millisecond {
+		t.Fatalf("Expected a spike predicted within 5ms, got %s (%t).",
+			next.Sub(now), ok)
+	}
+	if _, fired := iz.AddPotentialAt(0, next.Add(-IZHIKEVICH_STEP)); fired {
+		t.Errorf("Unexpected firing one step before the predicted spike.")
+	}
+	if _, fired := iz.AddPotentialAt(0, next); !fired {
+		t.Errorf("Expected firing at the predicted spike.")
+	}
+	if iz.LastSpike() != next {
+		t.Errorf("Expected the spike recorded at %s, got %s.", next, iz.LastSpike())
+	}
+}
+
+func TestIzhikevichNextSpikeBursts(t *testing.T) {
+	// Chattering neurons fire bursts of closely spaced spikes, each of
+	// which is predicted separately.
+	iz := NewIzhikevich(CHATTERING)
+	iz.Current = 10
+	iz.GetPotentialAt(now)
+	duration := 200 * time.Millisecond
+
+	spikes, _ := follow(iz, now.Add(duration))
+	predicted := len(spikes)
+
+	stepped := NewIzhikevich(CHATTERING)
+	stepped.Current = 10
+	expected := 0
+	for t := time.Duration(0); t <= duration; t += IZHIKEVICH_STEP {
+		if _, fired := stepped.AddPotentialAt(0, now.Add(t)); fired {
+			expected += 1
+		}
+	}
+	if predicted == 0 || predicted != expected {
+		t.Errorf("Expected %d spikes, predicted %d.", expected, predicted)
+	}
+}
@@ -0,0 +1,129 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"github.com/absoludity/go-neuron/clock"
+	"math"
+	"time"
+)
+
+// The LeakyIntegrateAndFire relaxes towards the resting potential with
+// a default membrane time constant typical of cortical neurons, while
+// the active and inactive durations match those of the Simple.
+const (
+	LIF_TIME_CONSTANT     = 10 * time.Millisecond
+	LIF_ACTIVE_DURATION   = 3 * time.Millisecond
+	LIF_INACTIVE_DURATION = 3 * time.Millisecond
+)
+
+// A LeakyIntegrateAndFire is an action potential whose membrane
+// potential relaxes exponentially towards the resting potential,
+// rather than being reset once a fixed duration has passed. After
+// the inactive period the potential starts from the refractory
+// potential and relaxes back to rest in the same way.
+//
+// http://en.wikipedia.org/wiki/Biological_neuron_model#Leaky_integrate-and-fire
+type LeakyIntegrateAndFire struct {
+	PotentialState
+	// TimeConstant is the membrane time constant. The zero value
+	// uses LIF_TIME_CONSTANT.
+	TimeConstant time.Duration
+	// Clock provides the time for GetPotential and AddPotential.
+	// The nil value uses the system clock.
+	Clock clock.Clock
+}
+
+func NewLeakyIntegrateAndFire(time_constant time.Duration) *LeakyIntegrateAndFire {
+	return &LeakyIntegrateAndFire{TimeConstant: time_constant}
+}
+
+func (lif *LeakyIntegrateAndFire) timeConstant() time.Duration {
+	if lif.TimeConstant <= 0 {
+		return LIF_TIME_CONSTANT
+	}
+	return lif.TimeConstant
+}
+
+// GetPotentialAt determines and returns the potential at a given
+// point in time. The decay while deactivated is calculated
+// analytically from the last change, so the result does not depend
+// on how often the potential is queried.
+func (lif *LeakyIntegrateAndFire) GetPotentialAt(now time.Time) Potential {
+	if lif.state == ACTIVATED {
+		inactive_time := lif.last_change.Add(LIF_ACTIVE_DURATION)
+		if inactive_time.Before(now) {
+			lif.last_potential = REFRACTORY_POTENTIAL
+			lif.state = INACTIVATED
+			lif.last_change = inactive_time
+		}
+	}
+	if lif.state == INACTIVATED {
+		deactivated_time := lif.last_change.Add(LIF_INACTIVE_DURATION)
+		if deactivated_time.Before(now) {
+			lif.state = DEACTIVATED
+			lif.last_change = deactivated_time
+		}
+	}
+	if lif.state == DEACTIVATED && now.After(lif.last_change) {
+		elapsed := now.Sub(lif.last_change)
+		decay := math.Exp(-float64(elapsed) / float64(lif.timeConstant()))
+		return REST_POTENTIAL + Potential(float64(lif.last_potential-REST_POTENTIAL)*decay)
+	}
+	return lif.last_potential
+}
+
+// GetPotential determines and returns the potential at the time it
+// is called.
+func (lif *LeakyIntegrateAndFire) GetPotential() Potential {
+	return lif.GetPotentialAt(clock.OrSystem(lif.Clock).Now())
+}
+
+// AddPotentialAt adds the specified potential to the decayed
+// potential at the specified time.
+func (lif *LeakyIntegrateAndFire) AddPotentialAt(potential Potential, now time.Time) (Potential, bool) {
+	fired := false
+	current_potential := lif.GetPotentialAt(now)
+	if lif.state != DEACTIVATED {
+		return current_potential, fired
+	}
+	lif.last_potential = current_potential + potential
+	if now.After(lif.last_change) {
+		lif.last_change = now
+	}
+	if lif.last_potential > THRESHOLD_POTENTIAL {
+		lif.state = ACTIVATED
+		lif.last_potential = PEAK_POTENTIAL
+		fired = true
+	}
+	return lif.last_potential, fired
+}
+
+// AddPotential adds the specified potential to the decayed
+// potential at the time it is called.
+func (lif *LeakyIntegrateAndFire) AddPotential(potential Potential) (Potential, bool) {
+	return lif.AddPotentialAt(potential, clock.OrSystem(lif.Clock).Now())
+}
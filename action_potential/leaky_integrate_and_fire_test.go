@@ -0,0 +1,132 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func approximately(a, b Potential) bool {
+	return math.Abs(float64(a-b)) < 0.001
+}
+
+func TestLeakyIntegrateAndFireGetPotentialAt(t *testing.T) {
+	tau := 10 * time.Millisecond
+	cases := []struct {
+		in  PotentialState
+		at  time.Time
+		out Potential
+	}{
+		// A deactivated cell decays to 1/e of its potential after
+		// one time constant.
+		{PotentialState{10, now, DEACTIVATED}, now.Add(tau), Potential(10 / math.E)},
+		// A deactivated cell decays to 1/e^2 after two time constants.
+		{PotentialState{10, now, DEACTIVATED}, now.Add(2 * tau), Potential(10 / math.E / math.E)},
+		// A deactivated cell's potential is unchanged at the time of
+		// the last change.
+		{PotentialState{10, now, DEACTIVATED}, now, 10},
+		// An activated cell remains at peak for the active duration.
+		{PotentialState{PEAK_POTENTIAL, now, ACTIVATED}, now.Add(LIF_ACTIVE_DURATION - time.Microsecond), PEAK_POTENTIAL},
+		// An activated cell is refractory after the active duration.
+		{PotentialState{PEAK_POTENTIAL, now, ACTIVATED}, now.Add(LIF_ACTIVE_DURATION + time.Microsecond), REFRACTORY_POTENTIAL},
+		// After the inactive duration, the refractory potential
+		// relaxes back towards rest.
+		{
+			PotentialState{REFRACTORY_POTENTIAL, now, INACTIVATED},
+			now.Add(LIF_INACTIVE_DURATION + tau),
+			Potential(float64(REFRACTORY_POTENTIAL) / math.E),
+		},
+	}
+
+	for i, tt := range cases {
+		lif := LeakyIntegrateAndFire{PotentialState: tt.in, TimeConstant: tau}
+
+		actual_potential := lif.GetPotentialAt(tt.at)
+
+		if !approximately(actual_potential, tt.out) {
+			t.Errorf("%d: Expected potential: %.3f, actual: %.3f",
+				i, tt.out, actual_potential)
+		}
+	}
+}
+
+func TestLeakyIntegrateAndFireSumsPartially(t *testing.T) {
+	// Unlike the Simple, two inputs just beyond the simple decay
+	// duration still sum, but only partially.
+	lif := NewLeakyIntegrateAndFire(10 * time.Millisecond)
+	lif.AddPotentialAt(10, now)
+	at := now.Add(SIMPLE_DECAY_DURATION + 100*time.Microsecond)
+
+	actual_potential, fired := lif.AddPotentialAt(4, at)
+
+	if fired {
+		t.Error("Unexpected firing of action potential.")
+	}
+	if actual_potential <= 4 || actual_potential >= 14 {
+		t.Errorf("Expected a partial sum in (4, 14), actual %.3f.",
+			actual_potential)
+	}
+}
+
+func TestLeakyIntegrateAndFireFires(t *testing.T) {
+	lif := NewLeakyIntegrateAndFire(10 * time.Millisecond)
+	lif.AddPotentialAt(10, now)
+
+	actual_potential, fired := lif.AddPotentialAt(10, now.Add(time.Millisecond))
+
+	if !fired {
+		t.Error("Expected action potential to fire, but didn't.")
+	}
+	if actual_potential != PEAK_POTENTIAL {
+		t.Errorf("Expected potential %.1f, actual %.1f.",
+			PEAK_POTENTIAL, actual_potential)
+	}
+	if lif.state != ACTIVATED {
+		t.Errorf("Expected state %s, actual %s.", ACTIVATED, lif.state)
+	}
+
+	// Further additions are ignored while activated.
+	actual_potential, fired = lif.AddPotentialAt(10, now.Add(2*time.Millisecond))
+
+	if fired || actual_potential != PEAK_POTENTIAL {
+		t.Errorf("Expected activated cell to ignore additions, got %.1f "+
+			"(fired: %t).", actual_potential, fired)
+	}
+}
+
+func TestLeakyIntegrateAndFireDefaultTimeConstant(t *testing.T) {
+	lif := new(LeakyIntegrateAndFire)
+	lif.AddPotentialAt(10, now)
+
+	actual_potential := lif.GetPotentialAt(now.Add(LIF_TIME_CONSTANT))
+
+	if !approximately(actual_potential, Potential(10/math.E)) {
+		t.Errorf("Expected potential: %.3f, actual: %.3f",
+			10/math.E, actual_potential)
+	}
+}
@@ -0,0 +1,132 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// A ReplayResult records the outcome of replaying a single event.
+type ReplayResult struct {
+	Event     AddPotentialEvent
+	Potential Potential
+	Fired     bool
+}
+
+// Replay adds the potential of each event to the action potential at
+// the event's simulated Time, in the order recorded, and returns the
+// resulting potential and whether it fired for each event. The
+// RealTime of the events is ignored.
+func Replay(events []AddPotentialEvent, ap ActionPotential) []ReplayResult {
+	results := make([]ReplayResult, len(events))
+	for i, e := range events {
+		potential, fired := ap.AddPotentialAt(e.Potential, e.Time)
+		results[i] = ReplayResult{e, potential, fired}
+	}
+	return results
+}
+
+// A recording is saved as a header line identifying the format and
+// version, followed by one JSON object per event.
+const EVENTS_FORMAT = "go-neuron/events"
+
+type eventsHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+type eventJSON struct {
+	Potential Potential `json:"potential"`
+	Time      time.Time `json:"time"`
+	RealTime  time.Time `json:"real_time"`
+	Result    Potential `json:"result"`
+	Fired     bool      `json:"fired"`
+}
+
+// An EventWriter saves events to a writer as they are written, in the
+// format read by ReadEvents.
+type EventWriter struct {
+	encoder        *json.Encoder
+	header_written bool
+}
+
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{encoder: json.NewEncoder(w)}
+}
+
+// Write saves the event, preceded by the header if it is the first.
+func (ew *EventWriter) Write(e AddPotentialEvent) error {
+	if !ew.header_written {
+		if err := ew.encoder.Encode(eventsHeader{EVENTS_FORMAT, ENCODING_VERSION}); err != nil {
+			return err
+		}
+		ew.header_written = true
+	}
+	return ew.encoder.Encode(eventJSON{e.Potential, e.Time, e.RealTime, e.Result, e.Fired})
+}
+
+// WriteEvents saves a recording of events to the writer.
+func WriteEvents(w io.Writer, events []AddPotentialEvent) error {
+	ew := NewEventWriter(w)
+	for _, e := range events {
+		if err := ew.Write(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadEvents loads a recording of events saved by WriteEvents or an
+// EventWriter. An empty reader is an empty recording.
+func ReadEvents(r io.Reader) ([]AddPotentialEvent, error) {
+	decoder := json.NewDecoder(r)
+	var header eventsHeader
+	if err := decoder.Decode(&header); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if header.Format != EVENTS_FORMAT {
+		return nil, fmt.Errorf("unknown recording format %q", header.Format)
+	}
+	if err := checkVersion(header.Version); err != nil {
+		return nil, err
+	}
+
+	var events []AddPotentialEvent
+	for {
+		var e eventJSON
+		if err := decoder.Decode(&e); err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return events, err
+		}
+		events = append(events, AddPotentialEvent{e.Potential, e.Time, e.RealTime, e.Result, e.Fired})
+	}
+}
@@ -0,0 +1,112 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	recorder := NewEventRecorder(new(Simple))
+	inputs := []struct {
+		potential Potential
+		at        time.Time
+	}{
+		{5, now},
+		{5, now.Add(time.Millisecond)},
+		{6, now.Add(2 * time.Millisecond)},
+		{1, now.Add(10 * time.Millisecond)},
+	}
+	var expected []ReplayResult
+	for _, in := range inputs {
+		potential, fired := recorder.AddPotentialAt(in.potential, in.at)
+		expected = append(expected, ReplayResult{Potential: potential, Fired: fired})
+	}
+
+	results := Replay(recorder.Events, new(Simple))
+
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, received %d.", len(expected), len(results))
+	}
+	for i, r := range results {
+		if r.Event != recorder.Events[i] {
+			t.Errorf("%d: Expected event %v, actual %v.", i, recorder.Events[i], r.Event)
+		}
+		if r.Potential != expected[i].Potential || r.Fired != expected[i].Fired {
+			t.Errorf("%d: Expected %.1f (fired: %t), actual %.1f (fired: %t).",
+				i, expected[i].Potential, expected[i].Fired, r.Potential, r.Fired)
+		}
+	}
+}
+
+func TestWriteReadEvents(t *testing.T) {
+	events := []AddPotentialEvent{
+		{1.5, now, now.Add(time.Microsecond), 1.5, false},
+		{-2, now.Add(time.Millisecond), now.Add(time.Millisecond + time.Microsecond), PEAK_POTENTIAL, true},
+	}
+	var buf bytes.Buffer
+
+	if err := WriteEvents(&buf, events); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	loaded, err := ReadEvents(&buf)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(loaded) != len(events) {
+		t.Fatalf("Expected %d events, loaded %d.", len(events), len(loaded))
+	}
+	for i, e := range loaded {
+		if e.Potential != events[i].Potential || !e.Time.Equal(events[i].Time) ||
+			!e.RealTime.Equal(events[i].RealTime) ||
+			e.Result != events[i].Result || e.Fired != events[i].Fired {
+			t.Errorf("%d: Expected event %v, actual %v.", i, events[i], e)
+		}
+	}
+}
+
+func TestReadEventsErrors(t *testing.T) {
+	invalid := []string{
+		`{"format":"something-else","version":1}`,
+		`{"format":"go-neuron/events","version":2}`,
+		`{"format":"go-neuron/events","version":1}` + "\n" + `{"potential":"high"}`,
+	}
+
+	for i, data := range invalid {
+		if _, err := ReadEvents(strings.NewReader(data)); err == nil {
+			t.Errorf("%d: Expected an error reading %s.", i, data)
+		}
+	}
+
+	events, err := ReadEvents(strings.NewReader(""))
+	if err != nil || len(events) != 0 {
+		t.Errorf("Expected an empty recording, got %v (%v).", events, err)
+	}
+}
@@ -26,10 +26,12 @@
 package action_potential
 
 import (
+	"fmt"
+	"github.com/absoludity/go-neuron/clock"
 	"time"
 )
 
-// The Simple activates for a duration
+// By default the Simple activates for a duration
 // when the initial threshold is reached, then
 // is inactive for a duration before switching back to deactivated.
 const (
@@ -38,31 +40,99 @@ const (
 	SIMPLE_INACTIVE_DURATION = 3 * time.Millisecond
 )
 
+// SimpleParams are the potentials and durations which determine
+// the behaviour of a Simple.
+type SimpleParams struct {
+	Threshold        Potential
+	Peak             Potential
+	Refractory       Potential
+	DecayDuration    time.Duration
+	ActiveDuration   time.Duration
+	InactiveDuration time.Duration
+}
+
+// DEFAULT_SIMPLE_PARAMS are used by any Simple not created with
+// NewSimple, such as new(Simple).
+var DEFAULT_SIMPLE_PARAMS = SimpleParams{
+	Threshold:        THRESHOLD_POTENTIAL,
+	Peak:             PEAK_POTENTIAL,
+	Refractory:       REFRACTORY_POTENTIAL,
+	DecayDuration:    SIMPLE_DECAY_DURATION,
+	ActiveDuration:   SIMPLE_ACTIVE_DURATION,
+	InactiveDuration: SIMPLE_INACTIVE_DURATION,
+}
+
+// Validate returns an error describing the first problem with the
+// params, or nil if they are valid.
+func (p SimpleParams) Validate() error {
+	switch {
+	case p.Threshold <= REST_POTENTIAL:
+		return fmt.Errorf("threshold potential %.1f must be above the "+
+			"rest potential %.1f", p.Threshold, REST_POTENTIAL)
+	case p.Peak <= p.Threshold:
+		return fmt.Errorf("peak potential %.1f must be above the "+
+			"threshold potential %.1f", p.Peak, p.Threshold)
+	case p.Refractory >= p.Threshold:
+		return fmt.Errorf("refractory potential %.1f must be below the "+
+			"threshold potential %.1f", p.Refractory, p.Threshold)
+	case p.DecayDuration <= 0:
+		return fmt.Errorf("decay duration %s must be positive", p.DecayDuration)
+	case p.ActiveDuration <= 0:
+		return fmt.Errorf("active duration %s must be positive", p.ActiveDuration)
+	case p.InactiveDuration <= 0:
+		return fmt.Errorf("inactive duration %s must be positive", p.InactiveDuration)
+	}
+	return nil
+}
+
 // The Simple is a simple implementation of
 // the action potential interface.
 type Simple struct {
 	PotentialState
+	// Clock provides the time for GetPotential and AddPotential.
+	// The nil value uses the system clock.
+	Clock clock.Clock
+	// The nil value uses DEFAULT_SIMPLE_PARAMS.
+	params *SimpleParams
+}
+
+// NewSimple returns a Simple using the given params, or an error if
+// the params are not valid.
+func NewSimple(params SimpleParams) (*Simple, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &Simple{params: &params}, nil
+}
+
+// Params returns the params used by the Simple.
+func (cb *Simple) Params() SimpleParams {
+	if cb.params == nil {
+		return DEFAULT_SIMPLE_PARAMS
+	}
+	return *cb.params
 }
 
 // GetPotentialAt determines and returns the potential at a given
 // point in time.
 func (cb *Simple) GetPotentialAt(now time.Time) Potential {
+	params := cb.Params()
 	switch cb.state {
 	case DEACTIVATED:
-		decay_time := cb.last_change.Add(SIMPLE_DECAY_DURATION)
+		decay_time := cb.last_change.Add(params.DecayDuration)
 		if decay_time.Before(now) {
 			cb.last_potential = 0
 			cb.last_change = now
 		}
 	case ACTIVATED:
-		inactive_time := cb.last_change.Add(SIMPLE_ACTIVE_DURATION)
+		inactive_time := cb.last_change.Add(params.ActiveDuration)
 		if inactive_time.Before(now) {
-			cb.last_potential = REFRACTORY_POTENTIAL
+			cb.last_potential = params.Refractory
 			cb.state = INACTIVATED
 			cb.last_change = inactive_time
 		}
 	case INACTIVATED:
-		deactivated_time := cb.last_change.Add(SIMPLE_ACTIVE_DURATION)
+		deactivated_time := cb.last_change.Add(params.InactiveDuration)
 		if deactivated_time.Before(now) {
 			cb.state = DEACTIVATED
 			cb.last_change = deactivated_time
@@ -75,21 +145,22 @@ func (cb *Simple) GetPotentialAt(now time.Time) Potential {
 // GetPotential determines and returns the potential at the time it
 // is called.
 func (cb *Simple) GetPotential() Potential {
-	return cb.GetPotentialAt(time.Now())
+	return cb.GetPotentialAt(clock.OrSystem(cb.Clock).Now())
 }
 
 // AddPotentialAt adds the specified potential based on the existing
 // potential at the specified time.
 func (cb *Simple) AddPotentialAt(potential Potential, now time.Time) (Potential, bool) {
+	params := cb.Params()
 	prev := cb.last_potential
 	fired := false
 	current_potential := cb.GetPotentialAt(now)
 	switch cb.state {
 	case DEACTIVATED:
 		cb.last_potential = current_potential + potential
-		if cb.last_potential > THRESHOLD_POTENTIAL {
+		if cb.last_potential > params.Threshold {
 			cb.state = ACTIVATED
-			cb.last_potential = PEAK_POTENTIAL
+			cb.last_potential = params.Peak
 			fired = true
 		}
 		if cb.last_potential != prev {
@@ -102,5 +173,5 @@ func (cb *Simple) AddPotentialAt(potential Potential, now time.Time) (Potential,
 // AddPotential adds the specified potential based on the existing
 // potential at the time it is called.
 func (cb *Simple) AddPotential(potential Potential) (Potential, bool) {
-	return cb.AddPotentialAt(potential, time.Now())
+	return cb.AddPotentialAt(potential, clock.OrSystem(cb.Clock).Now())
 }
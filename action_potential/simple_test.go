@@ -75,7 +75,7 @@ var get_potential_cases = []struct {
 
 func TestGetPotentialAt(t *testing.T) {
 	for i, tt := range get_potential_cases {
-		cb := Simple{tt.in}
+		cb := Simple{PotentialState: tt.in}
 
 		actual_potential := cb.GetPotentialAt(tt.at)
 
@@ -157,7 +157,7 @@ var add_potential_cases = []struct {
 
 func TestAddPotentialAt(t *testing.T) {
 	for i, tt := range add_potential_cases {
-		cb := Simple{tt.initial}
+		cb := Simple{PotentialState: tt.initial}
 
 		actual_potential, fired := cb.AddPotentialAt(tt.in, tt.at)
 
@@ -172,3 +172,70 @@ func TestAddPotentialAt(t *testing.T) {
 		verify(t, i, tt.final, cb.PotentialState)
 	}
 }
+
+func TestNewSimpleValidatesParams(t *testing.T) {
+	invalid := []func(*SimpleParams){
+		func(p *SimpleParams) { p.Threshold = REST_POTENTIAL },
+		func(p *SimpleParams) { p.Peak = p.Threshold },
+		func(p *SimpleParams) { p.Refractory = p.Threshold },
+		func(p *SimpleParams) { p.DecayDuration = 0 },
+		func(p *SimpleParams) { p.ActiveDuration = -time.Millisecond },
+		func(p *SimpleParams) { p.InactiveDuration = 0 },
+	}
+
+	for i, modify := range invalid {
+		params := DEFAULT_SIMPLE_PARAMS
+		modify(&params)
+
+		cb, err := NewSimple(params)
+
+		if err == nil || cb != nil {
+			t.Errorf("%d: Expected an error for params %+v.", i, params)
+		}
+	}
+
+	cb, err := NewSimple(DEFAULT_SIMPLE_PARAMS)
+	if err != nil {
+		t.Fatalf("Unexpected error for default params: %s", err)
+	}
+	if cb.Params() != DEFAULT_SIMPLE_PARAMS {
+		t.Errorf("Expected params %+v, actual %+v.",
+			DEFAULT_SIMPLE_PARAMS, cb.Params())
+	}
+}
+
+func TestSimpleParams(t *testing.T) {
+	params := SimpleParams{
+		Threshold:        5,
+		Peak:             50,
+		Refractory:       -5,
+		DecayDuration:    time.Millisecond,
+		ActiveDuration:   time.Millisecond,
+		InactiveDuration: 10 * time.Millisecond,
+	}
+	cb, err := NewSimple(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	actual_potential, fired := cb.AddPotentialAt(6, now)
+
+	if !fired || actual_potential != params.Peak {
+		t.Errorf("Expected to fire with potential %.1f, actual %.1f "+
+			"(fired: %t).", params.Peak, actual_potential, fired)
+	}
+	// The inactive duration is independent of the active duration.
+	for _, d := range []time.Duration{2 * time.Millisecond, 5 * time.Millisecond} {
+		actual_potential = cb.GetPotentialAt(now.Add(d))
+		if cb.state != INACTIVATED || actual_potential != params.Refractory {
+			t.Errorf("Expected to be inactivated at %.1f after %s, actual %s.",
+				params.Refractory, d, cb.PotentialState)
+		}
+	}
+	at := now.Add(params.ActiveDuration + params.InactiveDuration + time.Microsecond)
+	actual_potential = cb.GetPotentialAt(at)
+	if cb.state != DEACTIVATED || actual_potential != REST_POTENTIAL {
+		t.Errorf("Expected to be deactivated at rest, actual %s.",
+			cb.PotentialState)
+	}
+}
@@ -0,0 +1,90 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"github.com/absoludity/go-neuron/clock"
+	"sync"
+	"time"
+)
+
+// A Synchronized encapsulates an action potential and serializes
+// access to it, so that potential can be added from several
+// goroutines at once.
+type Synchronized struct {
+	ActionPotential
+	// Clock provides the time for GetPotential and AddPotential.
+	// The nil value uses the system clock.
+	Clock clock.Clock
+
+	mutex sync.Mutex
+}
+
+func NewSynchronized(ap ActionPotential) *Synchronized {
+	return &Synchronized{ActionPotential: ap}
+}
+
+func (s *Synchronized) GetPotentialAt(t time.Time) Potential {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.ActionPotential.GetPotentialAt(t)
+}
+
+func (s *Synchronized) GetPotential() Potential {
+	return s.GetPotentialAt(clock.OrSystem(s.Clock).Now())
+}
+
+func (s *Synchronized) AddPotentialAt(p Potential, t time.Time) (Potential, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.ActionPotential.AddPotentialAt(p, t)
+}
+
+func (s *Synchronized) AddPotential(p Potential) (Potential, bool) {
+	return s.AddPotentialAt(p, clock.OrSystem(s.Clock).Now())
+}
+
+// NextSpike returns the next spike of the encapsulated action
+// potential, if it is Spontaneous.
+func (s *Synchronized) NextSpike() (time.Time, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sp, ok := s.ActionPotential.(Spontaneous); ok {
+		return sp.NextSpike()
+	}
+	return time.Time{}, false
+}
+
+// FiredAt returns the time of the firing most recently reported by the
+// encapsulated action potential, if it is Retimed.
+func (s *Synchronized) FiredAt() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if r, ok := s.ActionPotential.(Retimed); ok {
+		return r.FiredAt()
+	}
+	return time.Time{}
+}
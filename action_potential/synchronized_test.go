@@ -0,0 +1,71 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package action_potential
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSynchronizedConcurrentAddPotentialAt(t *testing.T) {
+	recorder := NewEventRecorder(new(Simple))
+	ap := NewSynchronized(recorder)
+	writers := 10
+	additions := 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < additions; j++ {
+				ap.AddPotentialAt(0, now.Add(time.Duration(j)*time.Microsecond))
+				ap.GetPotentialAt(now.Add(time.Duration(j) * time.Microsecond))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(recorder.Events) != writers*additions {
+		t.Errorf("Expected %d events, received %d.",
+			writers*additions, len(recorder.Events))
+	}
+}
+
+func TestSynchronizedAddPotential(t *testing.T) {
+	ap := NewSynchronized(new(Simple))
+
+	actual_potential, fired := ap.AddPotential(THRESHOLD_POTENTIAL + 1)
+
+	if !fired {
+		t.Error("Expected action potential to fire, but didn't.")
+	}
+	if actual_potential != PEAK_POTENTIAL {
+		t.Errorf("Expected potential %.1f, actual %.1f.",
+			PEAK_POTENTIAL, actual_potential)
+	}
+}
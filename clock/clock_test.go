@@ -0,0 +1,62 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystem(t *testing.T) {
+	before := time.Now()
+	c := OrSystem(nil)
+
+	now := c.Now()
+	timer := c.NewTimer(time.Millisecond)
+	fired_at := <-timer.C()
+
+	if now.Before(before) {
+		t.Errorf("Expected system time after %s, got %s.", before, now)
+	}
+	if fired_at.Before(now.Add(time.Millisecond)) {
+		t.Errorf("Expected timer to fire after %s, fired at %s.",
+			now.Add(time.Millisecond), fired_at)
+	}
+	if timer.Stop() {
+		t.Error("Expected stopping an expired timer to return false.")
+	}
+}
+
+func TestOrSystem(t *testing.T) {
+	fake := NewFake(time.Unix(0, 0))
+
+	if OrSystem(fake) != fake {
+		t.Error("Expected OrSystem to return the given clock.")
+	}
+	if _, ok := OrSystem(nil).(System); !ok {
+		t.Error("Expected OrSystem to return the system clock for nil.")
+	}
+}
@@ -0,0 +1,135 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// A Fake is a Clock whose time only changes when it is advanced, at
+// which point any timers which have expired deliver the time. It is
+// safe for concurrent use.
+type Fake struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+type fakeTimer struct {
+	fake     *Fake
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	f := t.fake
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for i, timer := range f.timers {
+		if timer == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+// NewTimer returns a timer which expires once the clock is advanced
+// by the duration. A timer with a non-positive duration expires
+// immediately.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	t := &fakeTimer{f, f.now.Add(d), make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- f.now
+		return t
+	}
+	f.timers = append(f.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by the duration.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock forward to the given time, expiring each timer
+// whose deadline has been reached in order of deadline. Times before
+// the current time are ignored.
+func (f *Fake) Set(t time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if t.Before(f.now) {
+		return
+	}
+	f.now = t
+	sort.SliceStable(f.timers, func(i, j int) bool {
+		return f.timers[i].deadline.Before(f.timers[j].deadline)
+	})
+	expired := 0
+	for _, timer := range f.timers {
+		if timer.deadline.After(t) {
+			break
+		}
+		timer.ch <- t
+		expired += 1
+	}
+	f.timers = append(f.timers[:0], f.timers[expired:]...)
+}
+
+// NextTimer returns the earliest deadline of the timers which have
+// not yet expired or been stopped, and whether there are any.
+func (f *Fake) NextTimer() (time.Time, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if len(f.timers) == 0 {
+		return time.Time{}, false
+	}
+	next := f.timers[0].deadline
+	for _, timer := range f.timers[1:] {
+		if timer.deadline.Before(next) {
+			next = timer.deadline
+		}
+	}
+	return next, true
+}
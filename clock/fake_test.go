@@ -0,0 +1,111 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Unix(1000, 0)
+
+func expired(timer Timer) bool {
+	select {
+	case <-timer.C():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFakeAdvance(t *testing.T) {
+	fake := NewFake(start)
+	early := fake.NewTimer(time.Millisecond)
+	late := fake.NewTimer(3 * time.Millisecond)
+
+	fake.Advance(2 * time.Millisecond)
+
+	if fake.Now() != start.Add(2*time.Millisecond) {
+		t.Errorf("Expected time %s, got %s.", start.Add(2*time.Millisecond), fake.Now())
+	}
+	if !expired(early) {
+		t.Error("Expected the early timer to have expired.")
+	}
+	if expired(late) {
+		t.Error("Expected the late timer not to have expired.")
+	}
+	next, ok := fake.NextTimer()
+	if !ok || next != start.Add(3*time.Millisecond) {
+		t.Errorf("Expected next timer at %s, got %s (%t).",
+			start.Add(3*time.Millisecond), next, ok)
+	}
+
+	fake.Set(next)
+
+	if !expired(late) {
+		t.Error("Expected the late timer to have expired.")
+	}
+	if _, ok := fake.NextTimer(); ok {
+		t.Error("Expected no remaining timers.")
+	}
+}
+
+func TestFakeStop(t *testing.T) {
+	fake := NewFake(start)
+	timer := fake.NewTimer(time.Millisecond)
+
+	if !timer.Stop() {
+		t.Error("Expected stopping an active timer to return true.")
+	}
+	fake.Advance(time.Second)
+
+	if expired(timer) {
+		t.Error("Expected a stopped timer not to expire.")
+	}
+	if timer.Stop() {
+		t.Error("Expected stopping a stopped timer to return false.")
+	}
+}
+
+func TestFakeImmediateTimer(t *testing.T) {
+	fake := NewFake(start)
+
+	timer := fake.NewTimer(0)
+
+	if !expired(timer) {
+		t.Error("Expected a timer without a duration to expire immediately.")
+	}
+}
+
+func TestFakeSetBackwards(t *testing.T) {
+	fake := NewFake(start)
+
+	fake.Set(start.Add(-time.Second))
+
+	if fake.Now() != start {
+		t.Errorf("Expected time to remain %s, got %s.", start, fake.Now())
+	}
+}
@@ -0,0 +1,592 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+/*
+	Package neuroml imports networks described in the subset of NeuroML v2
+	which maps onto go-neuron's neurons and action potentials.
+
+	The supported elements are:
+
+		iafCell, iafRefCell   a LeakyIntegrateAndFire with the time constant
+		                      C / leakConductance, whose thresh, reset and
+		                      refract must match the model
+		izhikevichCell        an Izhikevich with the a, b, c and d parameters,
+		                      whose v0 and thresh must match the model
+		expOneSynapse,        synapses, which deliver their weighted potential
+		expTwoSynapse,        instantaneously
+		alphaSynapse
+		pulseGenerator        a current pulse, for explicit inputs
+		network               a single network of
+		population            neurons, listed or of a size
+		projection            synapses between populations, added with
+		                      connection or connectionWD
+		explicitInput,        pulse generators applied to neurons
+		inputList
+
+	A LeakyIntegrateAndFire fires THRESHOLD_POTENTIAL mV above its resting
+	potential and restarts REFRACTORY_POTENTIAL mV from it after being
+	refractory for LIF_ACTIVE_DURATION plus LIF_INACTIVE_DURATION, so the
+	thresh and reset of an iafCell, relative to its leakReversal, and the
+	refract of an iafRefCell must match those; other values are an error.
+	An iafCell, which has no refract, is given the model's refractory
+	period. Likewise, an Izhikevich starts at IZHIKEVICH_INITIAL_POTENTIAL
+	and spikes at IZHIKEVICH_PEAK_POTENTIAL, so the v0 and thresh of an
+	izhikevichCell must match those. Each synapse adds the connection's
+	weight times Options.UnitPotential to the target. Metadata such as
+	notes, annotations and properties are ignored, while any other element
+	is an error rather than being dropped.
+*/
+package neuroml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/absoludity/go-neuron/action_potential"
+	"github.com/absoludity/go-neuron/neuron"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Options configure an import.
+type Options struct {
+	// UnitPotential is the potential added by a synapse of weight
+	// one. The zero value uses neuron.DEFAULT_WEIGHT.
+	UnitPotential action_potential.Potential
+	// Buffer is the number of activation events the network's stream
+	// can buffer. The zero value buffers one per neuron.
+	Buffer int
+}
+
+// An Input is a current pulse applied to a neuron, from a
+// pulseGenerator. Applying it is left to the caller, as only some
+// models, such as the Izhikevich, take an input current.
+type Input struct {
+	Neuron neuron.NeuronID
+	// Delay is the time from the start of the simulation until the
+	// pulse starts.
+	Delay    time.Duration
+	Duration time.Duration
+	// Amplitude is the current in amperes.
+	Amplitude float64
+}
+
+// An Import is the result of importing a NeuroML document. Each of its
+// populations is added to the Network with AddPopulation, using its
+// NeuroML id as the name.
+type Import struct {
+	Network *neuron.Network
+	Inputs  []Input
+}
+
+// element records an element which is not otherwise supported.
+type element struct {
+	XMLName xml.Name
+}
+
+// metadata holds the elements which are ignored wherever they appear.
+type metadata struct {
+	Notes       []element `xml:"notes"`
+	Annotations []element `xml:"annotation"`
+	Properties  []element `xml:"property"`
+}
+
+type document struct {
+	metadata
+	IafCells        []iafCell        `xml:"iafCell"`
+	IafRefCells     []iafCell        `xml:"iafRefCell"`
+	IzhikevichCells []izhikevichCell `xml:"izhikevichCell"`
+	ExpOneSynapses  []synapse        `xml:"expOneSynapse"`
+	ExpTwoSynapses  []synapse        `xml:"expTwoSynapse"`
+	AlphaSynapses   []synapse        `xml:"alphaSynapse"`
+	PulseGenerators []pulseGenerator `xml:"pulseGenerator"`
+	Networks        []network        `xml:"network"`
+	Unsupported     []element        `xml:",any"`
+}
+
+type iafCell struct {
+	metadata
+	ID              string    `xml:"id,attr"`
+	C               string    `xml:"C,attr"`
+	LeakConductance string    `xml:"leakConductance,attr"`
+	LeakReversal    string    `xml:"leakReversal,attr"`
+	Thresh          string    `xml:"thresh,attr"`
+	Reset           string    `xml:"reset,attr"`
+	Refract         string    `xml:"refract,attr"`
+	Unsupported     []element `xml:",any"`
+}
+
+type izhikevichCell struct {
+	metadata
+	ID          string    `xml:"id,attr"`
+	V0          string    `xml:"v0,attr"`
+	Thresh      string    `xml:"thresh,attr"`
+	A           string    `xml:"a,attr"`
+	B           string    `xml:"b,attr"`
+	C           string    `xml:"c,attr"`
+	D           string    `xml:"d,attr"`
+	Unsupported []element `xml:",any"`
+}
+
+type synapse struct {
+	metadata
+	ID          string    `xml:"id,attr"`
+	Unsupported []element `xml:",any"`
+}
+
+type pulseGenerator struct {
+	metadata
+	ID          string    `xml:"id,attr"`
+	Delay       string    `xml:"delay,attr"`
+	Duration    string    `xml:"duration,attr"`
+	Amplitude   string    `xml:"amplitude,attr"`
+	Unsupported []element `xml:",any"`
+}
+
+type network struct {
+	metadata
+	ID             string          `xml:"id,attr"`
+	Populations    []population    `xml:"population"`
+	Projections    []projection    `xml:"projection"`
+	ExplicitInputs []explicitInput `xml:"explicitInput"`
+	InputLists     []inputList     `xml:"inputList"`
+	Unsupported    []element       `xml:",any"`
+}
+
+type population struct {
+	metadata
+	ID          string     `xml:"id,attr"`
+	Component   string     `xml:"component,attr"`
+	Size        string     `xml:"size,attr"`
+	Instances   []instance `xml:"instance"`
+	Unsupported []element  `xml:",any"`
+}
+
+type instance struct {
+	metadata
+	ID          int       `xml:"id,attr"`
+	Locations   []element `xml:"location"`
+	Unsupported []element `xml:",any"`
+}
+
+type projection struct {
+	metadata
+	ID            string       `xml:"id,attr"`
+	Pre           string       `xml:"presynapticPopulation,attr"`
+	Post          string       `xml:"postsynapticPopulation,attr"`
+	Synapse       string       `xml:"synapse,attr"`
+	Connections   []connection `xml:"connection"`
+	ConnectionWDs []connection `xml:"connectionWD"`
+	Unsupported   []element    `xml:",any"`
+}
+
+type connection struct {
+	metadata
+	PreCellID   string    `xml:"preCellId,attr"`
+	PostCellID  string    `xml:"postCellId,attr"`
+	Weight      string    `xml:"weight,attr"`
+	Delay       string    `xml:"delay,attr"`
+	Unsupported []element `xml:",any"`
+}
+
+type explicitInput struct {
+	metadata
+	Target      string    `xml:"target,attr"`
+	Input       string    `xml:"input,attr"`
+	Unsupported []element `xml:",any"`
+}
+
+type inputList struct {
+	metadata
+	ID          string    `xml:"id,attr"`
+	Component   string    `xml:"component,attr"`
+	Population  string    `xml:"population,attr"`
+	Inputs      []input   `xml:"input"`
+	Unsupported []element `xml:",any"`
+}
+
+type input struct {
+	metadata
+	Target      string    `xml:"target,attr"`
+	Unsupported []element `xml:",any"`
+}
+
+// checkSupported returns an error naming the first unsupported
+// element, if any.
+func checkSupported(parent string, unsupported []element) error {
+	if len(unsupported) > 0 {
+		return fmt.Errorf("unsupported element <%s> in <%s>", unsupported[0].XMLName.Local, parent)
+	}
+	return nil
+}
+
+// Read imports the NeuroML document from the reader.
+func Read(r io.Reader, opts Options) (*Import, error) {
+	var doc document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := checkSupported("neuroml", doc.Unsupported); err != nil {
+		return nil, err
+	}
+	if len(doc.Networks) != 1 {
+		return nil, fmt.Errorf("expected one <network>, found %d", len(doc.Networks))
+	}
+	importer := &importer{
+		opts:      opts,
+		cells:     make(map[string]func() action_potential.ActionPotential),
+		synapses:  make(map[string]bool),
+		pulses:    make(map[string]pulseGenerator),
+		instances: make(map[string]map[int]int),
+	}
+	if importer.opts.UnitPotential == 0 {
+		importer.opts.UnitPotential = neuron.DEFAULT_WEIGHT
+	}
+	if err := importer.components(&doc); err != nil {
+		return nil, err
+	}
+	return importer.network(&doc.Networks[0])
+}
+
+type importer struct {
+	opts  Options
+	cells map[string]func() action_potential.ActionPotential
+	// The ids of the supported synapses.
+	synapses map[string]bool
+	pulses   map[string]pulseGenerator
+	net      *neuron.Network
+	// The index within its population of each instance id, for
+	// populations listing their instances.
+	instances map[string]map[int]int
+}
+
+// checkIafCell returns an error unless the potentials and refractory
+// period of the cell, which has refract if refractory, match those of
+// the LeakyIntegrateAndFire.
+func checkIafCell(cell iafCell, refractory bool) error {
+	var potentials [3]float64
+	for i, name := range []string{"leakReversal", "thresh", "reset"} {
+		text := []string{cell.LeakReversal, cell.Thresh, cell.Reset}[i]
+		var err error
+		if potentials[i], err = parseQuantity(text, voltage_units); err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+	}
+	leak_reversal, thresh, reset := potentials[0], potentials[1], potentials[2]
+	if !matches(thresh-leak_reversal, float64(action_potential.THRESHOLD_POTENTIAL)*1e-3) {
+		return fmt.Errorf("thresh must be %gmV above leakReversal", action_potential.THRESHOLD_POTENTIAL)
+	}
+	if !matches(reset-leak_reversal, float64(action_potential.REFRACTORY_POTENTIAL)*1e-3) {
+		return fmt.Errorf("reset must be %gmV from leakReversal", action_potential.REFRACTORY_POTENTIAL)
+	}
+	if !refractory {
+		if cell.Refract != "" {
+			return fmt.Errorf("refract is only supported on iafRefCell")
+		}
+		return nil
+	}
+	refract, err := parseDuration(cell.Refract)
+	if err != nil {
+		return fmt.Errorf("refract: %s", err)
+	}
+	if expected := action_potential.LIF_ACTIVE_DURATION + action_potential.LIF_INACTIVE_DURATION; refract != expected {
+		return fmt.Errorf("refract must be %s", expected)
+	}
+	return nil
+}
+
+// checkIzhikevichCell returns an error unless the initial and peak
+// potentials of the cell match those of the Izhikevich.
+func checkIzhikevichCell(cell izhikevichCell) error {
+	attributes := []struct {
+		name, text string
+		expected   action_potential.Potential
+	}{
+		{"v0", cell.V0, action_potential.IZHIKEVICH_INITIAL_POTENTIAL},
+		{"thresh", cell.Thresh, action_potential.IZHIKEVICH_PEAK_POTENTIAL},
+	}
+	for _, attribute := range attributes {
+		potential, err := parseQuantity(attribute.text, voltage_units)
+		if err != nil {
+			return fmt.Errorf("%s: %s", attribute.name, err)
+		}
+		if !matches(potential, float64(attribute.expected)*1e-3) {
+			return fmt.Errorf("%s must be %gmV", attribute.name, attribute.expected)
+		}
+	}
+	return nil
+}
+
+// matches returns whether the potentials, in volts, are equal to within
+// the precision of their text.
+func matches(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// components records the cells, synapses and pulse generators of the
+// document by id.
+func (im *importer) components(doc *document) error {
+	for i, cell := range append(append([]iafCell{}, doc.IafCells...), doc.IafRefCells...) {
+		if err := checkSupported("iafCell", cell.Unsupported); err != nil {
+			return err
+		}
+		if err := checkIafCell(cell, i >= len(doc.IafCells)); err != nil {
+			return fmt.Errorf("cell %q: %s", cell.ID, err)
+		}
+		capacitance, err := parseQuantity(cell.C, capacitance_units)
+		if err != nil {
+			return fmt.Errorf("cell %q: %s", cell.ID, err)
+		}
+		conductance, err := parseQuantity(cell.LeakConductance, conductance_units)
+		if err != nil {
+			return fmt.Errorf("cell %q: %s", cell.ID, err)
+		}
+		if capacitance <= 0 || conductance <= 0 {
+			return fmt.Errorf("cell %q: capacitance and leak conductance must be positive", cell.ID)
+		}
+		time_constant := time.Duration(math.Round(capacitance / conductance * float64(time.Second)))
+		im.cells[cell.ID] = func() action_potential.ActionPotential {
+			return action_potential.NewLeakyIntegrateAndFire(time_constant)
+		}
+	}
+	for _, cell := range doc.IzhikevichCells {
+		if err := checkSupported("izhikevichCell", cell.Unsupported); err != nil {
+			return err
+		}
+		if err := checkIzhikevichCell(cell); err != nil {
+			return fmt.Errorf("cell %q: %s", cell.ID, err)
+		}
+		var values [4]float64
+		for i, text := range []string{cell.A, cell.B, cell.C, cell.D} {
+			var err error
+			if values[i], err = strconv.ParseFloat(text, 64); err != nil {
+				return fmt.Errorf("cell %q: invalid parameter %q", cell.ID, text)
+			}
+		}
+		params := action_potential.IzhikevichParams{A: values[0], B: values[1], C: values[2], D: values[3]}
+		im.cells[cell.ID] = func() action_potential.ActionPotential {
+			return action_potential.NewIzhikevich(params)
+		}
+	}
+	for _, synapses := range [][]synapse{doc.ExpOneSynapses, doc.ExpTwoSynapses, doc.AlphaSynapses} {
+		for _, s := range synapses {
+			if err := checkSupported("synapse", s.Unsupported); err != nil {
+				return err
+			}
+			im.synapses[s.ID] = true
+		}
+	}
+	for _, pg := range doc.PulseGenerators {
+		if err := checkSupported("pulseGenerator", pg.Unsupported); err != nil {
+			return err
+		}
+		im.pulses[pg.ID] = pg
+	}
+	return nil
+}
+
+// network builds the network and its inputs.
+func (im *importer) network(n *network) (*Import, error) {
+	if err := checkSupported("network", n.Unsupported); err != nil {
+		return nil, err
+	}
+	size := 0
+	sizes := make([]int, len(n.Populations))
+	for i, p := range n.Populations {
+		if err := checkSupported("population", p.Unsupported); err != nil {
+			return nil, err
+		}
+		if len(p.Instances) > 0 {
+			sizes[i] = len(p.Instances)
+		} else if s, err := strconv.Atoi(p.Size); err != nil || s < 0 {
+			return nil, fmt.Errorf("population %q has invalid size %q", p.ID, p.Size)
+		} else {
+			sizes[i] = s
+		}
+		size += sizes[i]
+	}
+	buffer := im.opts.Buffer
+	if buffer == 0 {
+		buffer = size
+	}
+	im.net = neuron.NewNetwork(buffer)
+
+	for i, p := range n.Populations {
+		model, ok := im.cells[p.Component]
+		if !ok {
+			return nil, fmt.Errorf("population %q has unknown or unsupported cell %q", p.ID, p.Component)
+		}
+		if im.net.Population(p.ID) != nil {
+			return nil, fmt.Errorf("population %q is repeated", p.ID)
+		}
+		if len(p.Instances) > 0 {
+			indices := make(map[int]int)
+			for j, inst := range p.Instances {
+				if err := checkSupported("instance", inst.Unsupported); err != nil {
+					return nil, err
+				}
+				indices[inst.ID] = j
+			}
+			im.instances[p.ID] = indices
+		}
+		im.net.AddPopulation(p.ID, sizes[i], model)
+	}
+
+	for _, p := range n.Projections {
+		if err := im.projection(p); err != nil {
+			return nil, fmt.Errorf("projection %q: %s", p.ID, err)
+		}
+	}
+
+	result := &Import{Network: im.net}
+	for _, ei := range n.ExplicitInputs {
+		if err := checkSupported("explicitInput", ei.Unsupported); err != nil {
+			return nil, err
+		}
+		in, err := im.input(ei.Input, ei.Target, "")
+		if err != nil {
+			return nil, err
+		}
+		result.Inputs = append(result.Inputs, in)
+	}
+	for _, il := range n.InputLists {
+		if err := checkSupported("inputList", il.Unsupported); err != nil {
+			return nil, err
+		}
+		for _, i := range il.Inputs {
+			if err := checkSupported("input", i.Unsupported); err != nil {
+				return nil, err
+			}
+			in, err := im.input(il.Component, i.Target, il.Population)
+			if err != nil {
+				return nil, fmt.Errorf("input list %q: %s", il.ID, err)
+			}
+			result.Inputs = append(result.Inputs, in)
+		}
+	}
+	return result, nil
+}
+
+func (im *importer) projection(p projection) error {
+	if err := checkSupported("projection", p.Unsupported); err != nil {
+		return err
+	}
+	if !im.synapses[p.Synapse] {
+		return fmt.Errorf("unknown or unsupported synapse %q", p.Synapse)
+	}
+	connections := []struct {
+		name string
+		list []connection
+	}{
+		{"connection", p.Connections},
+		{"connectionWD", p.ConnectionWDs},
+	}
+	for _, cs := range connections {
+		for _, c := range cs.list {
+			if err := checkSupported(cs.name, c.Unsupported); err != nil {
+				return err
+			}
+			pre, err := im.cell(c.PreCellID, p.Pre)
+			if err != nil {
+				return err
+			}
+			post, err := im.cell(c.PostCellID, p.Post)
+			if err != nil {
+				return err
+			}
+			weight, delay := 1.0, time.Duration(0)
+			if c.Weight != "" {
+				if weight, err = strconv.ParseFloat(c.Weight, 64); err != nil {
+					return fmt.Errorf("invalid weight %q", c.Weight)
+				}
+			}
+			if c.Delay != "" {
+				if delay, err = parseDuration(c.Delay); err != nil {
+					return err
+				}
+			}
+			potential := action_potential.Potential(weight) * im.opts.UnitPotential
+			if _, err := im.net.Connect(pre, post, potential, delay); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (im *importer) input(component, target, population_id string) (Input, error) {
+	pg, ok := im.pulses[component]
+	if !ok {
+		return Input{}, fmt.Errorf("unknown or unsupported input %q", component)
+	}
+	id, err := im.cell(target, population_id)
+	if err != nil {
+		return Input{}, err
+	}
+	in := Input{Neuron: id}
+	if in.Delay, err = parseDuration(pg.Delay); err != nil {
+		return in, fmt.Errorf("pulse generator %q: %s", pg.ID, err)
+	}
+	if in.Duration, err = parseDuration(pg.Duration); err != nil {
+		return in, fmt.Errorf("pulse generator %q: %s", pg.ID, err)
+	}
+	if in.Amplitude, err = parseQuantity(pg.Amplitude, current_units); err != nil {
+		return in, fmt.Errorf("pulse generator %q: %s", pg.ID, err)
+	}
+	return in, nil
+}
+
+// cell_reference matches references to cells, such as "pop[3]",
+// "../pop[3]" and "../pop/3/cellType".
+var cell_reference = regexp.MustCompile(`^(?:\.\./)?([^/\[\]]+)(?:\[(\d+)\]|/(\d+)(?:/[^/]*)?)$`)
+
+// cell returns the ID of the referenced cell, which must be in the
+// given population if one is given.
+func (im *importer) cell(reference, population_id string) (neuron.NeuronID, error) {
+	match := cell_reference.FindStringSubmatch(reference)
+	if match == nil {
+		return 0, fmt.Errorf("invalid cell reference %q", reference)
+	}
+	name, number := match[1], match[2]+match[3]
+	if population_id != "" && name != population_id {
+		return 0, fmt.Errorf("cell %q is not in population %q", reference, population_id)
+	}
+	ids := im.net.Population(name)
+	if ids == nil {
+		return 0, fmt.Errorf("cell %q is in an unknown population", reference)
+	}
+	index, _ := strconv.Atoi(number)
+	if indices, ok := im.instances[name]; ok {
+		if index, ok = indices[index]; !ok {
+			return 0, fmt.Errorf("cell %q is not an instance of its population", reference)
+		}
+	}
+	if index >= len(ids) {
+		return 0, fmt.Errorf("cell %q is out of range", reference)
+	}
+	return ids[index], nil
+}
@@ -0,0 +1,204 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuroml
+
+import (
+	"github.com/absoludity/go-neuron/action_potential"
+	"github.com/absoludity/go-neuron/neuron"
+	"strings"
+	"testing"
+	"time"
+)
+
+const exampleDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<neuroml xmlns="http://www.neuroml.org/schema/neuroml2" id="example">
+    <notes>An example network.</notes>
+    <iafCell id="iaf" leakReversal="-70mV" thresh="-55mV" reset="-85mV"
+        C="1.0nF" leakConductance="0.05uS"/>
+    <iafRefCell id="iafRef" leakReversal="-0.07V" thresh="-55mV" reset="-85mV"
+        C="0.2nF" leakConductance="0.02uS" refract="6ms"/>
+    <izhikevichCell id="rs" v0="-65mV" thresh="30mV" a="0.02" b="0.2" c="-65" d="8"/>
+    <expOneSynapse id="syn" gbase="1nS" erev="0mV" tauDecay="5ms"/>
+    <pulseGenerator id="pulse" delay="100ms" duration="500ms" amplitude="0.5nA"/>
+    <network id="net">
+        <population id="input" component="iaf" size="3"/>
+        <population id="listed" component="iafRef" type="populationList">
+            <instance id="10"><location x="0" y="0" z="0"/></instance>
+            <instance id="20"><location x="1" y="0" z="0"/></instance>
+        </population>
+        <population id="output" component="rs" size="1">
+            <property tag="colour" value="red"/>
+        </population>
+        <projection id="inToListed" presynapticPopulation="input"
+            postsynapticPopulation="listed" synapse="syn">
+            <connection id="0" preCellId="../input/0/iaf" postCellId="../listed/20/iafRef"/>
+            <connectionWD id="1" preCellId="../input[2]" postCellId="../listed[10]"
+                weight="2" delay="3ms"/>
+        </projection>
+        <projection id="listedToOut" presynapticPopulation="listed"
+            postsynapticPopulation="output" synapse="syn">
+            <connectionWD id="0" preCellId="../listed/10/iafRef" postCellId="../output/0/rs"
+                weight="-0.5" delay="1.5ms"/>
+        </projection>
+        <explicitInput target="input[1]" input="pulse"/>
+        <inputList id="inputs" component="pulse" population="output">
+            <input id="0" target="../output/0/rs" destination="synapses"/>
+        </inputList>
+    </network>
+</neuroml>`
+
+func TestRead(t *testing.T) {
+	imported, err := Read(strings.NewReader(exampleDocument), Options{UnitPotential: 4})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	net := imported.Network
+	input, listed, output := net.Population("input"), net.Population("listed"), net.Population("output")
+	if len(input) != 3 || len(listed) != 2 || len(output) != 1 {
+		t.Fatalf("Expected populations of 3, 2 and 1, actual %d, %d and %d.",
+			len(input), len(listed), len(output))
+	}
+	time_constants := []struct {
+		id       neuron.NeuronID
+		expected time.Duration
+	}{
+		{input[0], 20 * time.Millisecond},
+		{listed[1], 10 * time.Millisecond},
+	}
+	for _, tt := range time_constants {
+		lif, ok := net.Neuron(tt.id).ActionPotential.(*action_potential.LeakyIntegrateAndFire)
+		if !ok || lif.TimeConstant != tt.expected {
+			t.Errorf("Expected neuron %d to be leaky with time constant %s.", tt.id, tt.expected)
+		}
+	}
+	if iz, ok := net.Neuron(output[0]).ActionPotential.(*action_potential.Izhikevich); !ok || iz.IzhikevichParams != action_potential.REGULAR_SPIKING {
+		t.Errorf("Expected a regular spiking Izhikevich output neuron.")
+	}
+
+	synapses := []struct {
+		pre, post neuron.NeuronID
+		weight    action_potential.Potential
+		delay     time.Duration
+	}{
+		{input[0], listed[1], 4, 0},
+		{input[2], listed[0], 8, 3 * time.Millisecond},
+		{listed[0], output[0], -2, 1500 * time.Microsecond},
+	}
+	for _, tt := range synapses {
+		found := false
+		for _, s := range net.Neuron(tt.pre).Axon.Synapses {
+			if s.Target == net.Neuron(tt.post) {
+				found = true
+				if s.Weight != tt.weight || s.Delay != tt.delay {
+					t.Errorf("Expected synapse from %d to %d with weight %.1f and delay %s, actual %.1f and %s.",
+						tt.pre, tt.post, tt.weight, tt.delay, s.Weight, s.Delay)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Expected a synapse from %d to %d.", tt.pre, tt.post)
+		}
+	}
+
+	expected_inputs := []Input{
+		{input[1], 100 * time.Millisecond, 500 * time.Millisecond, 0.5e-9},
+		{output[0], 100 * time.Millisecond, 500 * time.Millisecond, 0.5e-9},
+	}
+	if len(imported.Inputs) != len(expected_inputs) {
+		t.Fatalf("Expected %d inputs, actual %d.", len(expected_inputs), len(imported.Inputs))
+	}
+	for i, in := range imported.Inputs {
+		expected := expected_inputs[i]
+		if in.Neuron != expected.Neuron || in.Delay != expected.Delay || in.Duration != expected.Duration ||
+			in.Amplitude < expected.Amplitude*0.999 || in.Amplitude > expected.Amplitude*1.001 {
+			t.Errorf("Expected input %v, actual %v.", expected, in)
+		}
+	}
+}
+
+func TestReadDefaultUnitPotential(t *testing.T) {
+	imported, err := Read(strings.NewReader(exampleDocument), Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	s := imported.Network.Neuron(1).Axon.Synapses[0]
+	if s.Weight != neuron.DEFAULT_WEIGHT {
+		t.Errorf("Expected weight %.1f, actual %.1f.", neuron.DEFAULT_WEIGHT, s.Weight)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	// Each replacement makes the example document unsupported or
+	// invalid.
+	tests := []struct {
+		old, new string
+		expected string
+	}{
+		{`<notes>An example network.</notes>`, `<include href="other.nml"/>`,
+			"unsupported element <include> in <neuroml>"},
+		{`<explicitInput`, `<synapticConnection from="a" to="b" synapse="syn"/><explicitInput`,
+			"unsupported element <synapticConnection> in <network>"},
+		{`<connection id="0"`, `<electricalConnection id="9"/><connection id="0"`,
+			"unsupported element <electricalConnection> in <projection>"},
+		{`component="rs"`, `component="hh"`, "unknown or unsupported cell"},
+		{`synapse="syn">`, `synapse="gap">`, "unknown or unsupported synapse"},
+		{`input="pulse"`, `input="sine"`, "unknown or unsupported input"},
+		{`C="1.0nF"`, `C="1.0nV"`, "unsupported unit"},
+		{`thresh="-55mV" reset="-85mV"
+        C="1.0nF"`, `thresh="-50mV" reset="-85mV"
+        C="1.0nF"`, "thresh must be 15mV above leakReversal"},
+		{`reset="-85mV"
+        C="1.0nF"`, `reset="-70mV"
+        C="1.0nF"`, "reset must be -15mV from leakReversal"},
+		{`leakReversal="-70mV"`, `leakReversal="-70nF"`, "leakReversal: quantity \"-70nF\" has an unsupported unit"},
+		{`thresh="-55mV" reset="-85mV"
+        C="1.0nF"`, `reset="-85mV"
+        C="1.0nF"`, "thresh: quantity \"\" has no unit"},
+		{`refract="6ms"`, `refract="2ms"`, "refract must be 6ms"},
+		{`refract="6ms"`, ``, "refract: quantity \"\" has no unit"},
+		{`leakConductance="0.05uS"/>`, `leakConductance="0.05uS" refract="6ms"/>`,
+			"refract is only supported on iafRefCell"},
+		{`v0="-65mV"`, `v0="-70mV"`, "v0 must be -65mV"},
+		{`thresh="30mV"`, `thresh="0.035V"`, "thresh must be 30mV"},
+		{`thresh="30mV"`, `thresh="30"`, "thresh: quantity \"30\" has no unit"},
+		{`size="3"`, `size="three"`, "invalid size"},
+		{`../listed[10]`, `../listed[11]`, "not an instance"},
+		{`input[1]`, `input[3]`, "out of range"},
+		{`../input/0/iaf`, `../missing/0/iaf`, "not in population"},
+		{`weight="2"`, `weight="heavy"`, "invalid weight"},
+		{`<network id="net">`, `<network id="other"/><network id="net">`, "expected one <network>"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(exampleDocument, tt.old) {
+			t.Fatalf("The example document does not contain %q.", tt.old)
+		}
+		document := strings.Replace(exampleDocument, tt.old, tt.new, 1)
+		_, err := Read(strings.NewReader(document), Options{})
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected an error containing %q, actual %v.", tt.expected, err)
+		}
+	}
+}
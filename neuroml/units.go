@@ -0,0 +1,84 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuroml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The SI scale of each supported unit, by dimension.
+var (
+	time_units = map[string]float64{
+		"s": 1, "ms": 1e-3, "us": 1e-6, "µs": 1e-6,
+	}
+	voltage_units = map[string]float64{
+		"V": 1, "mV": 1e-3,
+	}
+	capacitance_units = map[string]float64{
+		"F": 1, "uF": 1e-6, "nF": 1e-9, "pF": 1e-12,
+	}
+	conductance_units = map[string]float64{
+		"S": 1, "mS": 1e-3, "uS": 1e-6, "nS": 1e-9, "pS": 1e-12,
+	}
+	current_units = map[string]float64{
+		"A": 1, "mA": 1e-3, "uA": 1e-6, "nA": 1e-9, "pA": 1e-12,
+	}
+)
+
+// parseQuantity parses a NeuroML quantity such as "-70mV" or
+// "0.5 nA", returning its value in SI units.
+func parseQuantity(text string, units map[string]float64) (float64, error) {
+	text = strings.TrimSpace(text)
+	i := strings.IndexFunc(text, func(r rune) bool {
+		return !strings.ContainsRune("0123456789.-+eE", r)
+	})
+	if i < 0 {
+		return 0, fmt.Errorf("quantity %q has no unit", text)
+	}
+	number, unit := text[:i], strings.TrimSpace(text[i:])
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("quantity %q has an invalid number", text)
+	}
+	scale, ok := units[unit]
+	if !ok {
+		return 0, fmt.Errorf("quantity %q has an unsupported unit", text)
+	}
+	return value * scale, nil
+}
+
+// parseDuration parses a NeuroML time quantity such as "5ms".
+func parseDuration(text string) (time.Duration, error) {
+	seconds, err := parseQuantity(text, time_units)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(math.Round(seconds * float64(time.Second))), nil
+}
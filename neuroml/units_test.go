@@ -0,0 +1,79 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuroml
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		text     string
+		units    map[string]float64
+		expected float64
+	}{
+		{"-70mV", voltage_units, -0.07},
+		{"1.0 nF", capacitance_units, 1e-9},
+		{"0.05uS", conductance_units, 5e-8},
+		{"2.5e-1nA", current_units, 2.5e-10},
+		{"20ms", time_units, 0.02},
+	}
+	for _, tt := range tests {
+		actual, err := parseQuantity(tt.text, tt.units)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %s", tt.text, err)
+		}
+		if math.Abs(actual-tt.expected) > math.Abs(tt.expected)*1e-9 {
+			t.Errorf("Expected %q to be %g, actual %g.", tt.text, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseQuantityErrors(t *testing.T) {
+	tests := []struct {
+		text  string
+		units map[string]float64
+	}{
+		{"20", time_units},
+		{"ms", time_units},
+		{"20mV", time_units},
+		{"1.2.3ms", time_units},
+	}
+	for _, tt := range tests {
+		if _, err := parseQuantity(tt.text, tt.units); err == nil {
+			t.Errorf("Expected an error parsing %q.", tt.text)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	actual, err := parseDuration("0.3 ms")
+	if err != nil || actual != 300*time.Microsecond {
+		t.Errorf("Expected 300µs, actual %s (%v).", actual, err)
+	}
+}
@@ -26,73 +26,338 @@
 package neuron
 
 import (
-	"container/list"
+	"context"
+	"fmt"
+	"github.com/absoludity/go-neuron/clock"
+	"sync"
 	"time"
 )
 
 // An ActivationEvent records the neuron and time at which it
-// was activated.
+// was activated, along with the neuron's ID and Label so that
+// the event can be logged and compared across runs.
 type ActivationEvent struct {
 	Time   time.Time
 	Neuron *Neuron
+	ID     NeuronID
+	Label  string
 }
 
-type OrderedList struct {
-	list.List
+// A TerminalEvent records the neuron and the time at which
+// the signal reaches those of its axon terminals with the
+// given synapse delay, carrying the ID and Label of its
+// ActivationEvent.
+type TerminalEvent struct {
+	Time   time.Time
+	Neuron *Neuron
+	Delay  time.Duration
+	ID     NeuronID
+	Label  string
+
+	// wake is set for the event scheduled at the time the neuron's
+	// Spontaneous action potential next fires, which wakes the
+	// neuron rather than its terminals.
+	wake bool
+}
+
+// An ActivationStream communicates the activation events for further
+// processing.
+type ActivationStream chan ActivationEvent
+
+// An outbox collects the activation events of the neurons on a stream
+// while its terminal events are being delivered. Sending them to the
+// stream from the goroutine which receives from it would block forever
+// once its buffer was full, whether the neuron is a terminal itself or
+// is wrapped by one, such as by a Synchronized.
+//
+// It also collects the neurons whose Spontaneous action potential has
+// changed its prediction, at any time, so that the goroutine
+// processing the stream schedules a wake event for each without the
+// neuron sending to the stream.
+type outbox struct {
+	mutex      sync.Mutex
+	delivering bool
+	// detached is set once the outbox is removed from outboxes, so
+	// that neurons holding it find the current one.
+	detached bool
+	events   []ActivationEvent
+	wakes    []*Neuron
+	waking   map[*Neuron]bool
+	// ready is signalled when a neuron is added to wakes outside a
+	// delivery.
+	ready chan struct{}
+}
+
+// outboxes maps the channel of each stream to its outbox, so that
+// neurons holding different pointers to the same channel share it. An
+// outbox remains while the stream is being processed or wakes are
+// waiting in it.
+var outboxes sync.Map
+
+// outboxFor returns the outbox of the stream, adding one if needed.
+func outboxFor(as *ActivationStream) *outbox {
+	o, ok := outboxes.Load(*as)
+	if !ok {
+		o, _ = outboxes.LoadOrStore(*as, &outbox{
+			waking: make(map[*Neuron]bool),
+			ready:  make(chan struct{}, 1),
+		})
+	}
+	return o.(*outbox)
+}
+
+// post adds the activation event to the outbox of the stream, reporting
+// false if the stream's terminal events are not being delivered, in
+// which case the event should be sent to the stream instead.
+func post(as *ActivationStream, ae ActivationEvent) bool {
+	o, ok := outboxes.Load(*as)
+	if !ok {
+		return false
+	}
+	return o.(*outbox).add(ae)
+}
+
+// postWake adds the neuron to the outbox of its stream, so that a wake
+// event is scheduled for its next predicted spike.
+func postWake(n *Neuron) {
+	for !outboxFor(n.ActivationStream).addWake(n) {
+	}
+}
+
+func (o *outbox) add(ae ActivationEvent) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.delivering && !o.detached {
+		o.events = append(o.events, ae)
+	}
+	return o.delivering && !o.detached
 }
 
-func (l *OrderedList) Insert(value interface{}) *list.Element {
-	// Do benchmarks with built-in sort algorithm too. Very special
-	// case insert into sorted list.
-	event_time := value.(*TerminalEvent).Time
-	for e := l.Front(); e != nil; e = e.Next() {
-		if e.Value.(*TerminalEvent).Time.After(event_time) {
-			return l.InsertBefore(value, e)
+func (o *outbox) addWake(n *Neuron) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.detached {
+		return false
+	}
+	if !o.waking[n] {
+		o.waking[n] = true
+		o.wakes = append(o.wakes, n)
+	}
+	if !o.delivering {
+		select {
+		case o.ready <- struct{}{}:
+		default:
 		}
 	}
-	return l.PushBack(value)
+	return true
 }
 
-// A TerminalEvent records the neuron and the time at which
-// the signal reaches the axon terminals.
-type TerminalEvent ActivationEvent
+// attached reports whether the outbox is still in outboxes.
+func (o *outbox) attached() bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return !o.detached
+}
 
-// An ActivationStream communicates the activation events for further
-// processing.
-type ActivationStream chan ActivationEvent
+func (o *outbox) open() {
+	o.mutex.Lock()
+	o.delivering = true
+	o.mutex.Unlock()
+}
 
-func signalAxonTerminals(a Axon, t time.Time) {
-	for _, n := range a.Terminals {
-		// Should the potential for each be relative to total
-		// potential, or constant, or divided by the num of terminals?
-		n.AddPotentialAt(5.0, t)
+// take stops collecting events, returning those collected along with
+// the neurons to wake.
+func (o *outbox) take() ([]ActivationEvent, []*Neuron) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	events, wakes := o.events, o.wakes
+	o.delivering, o.events, o.wakes = false, nil, nil
+	clear(o.waking)
+	return events, wakes
+}
+
+// detach removes the outbox from outboxes unless wakes are waiting in
+// it for the next goroutine to process the stream.
+func (o *outbox) detach(ch ActivationStream) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.delivering = false
+	if len(o.wakes) == 0 {
+		o.detached = true
+		outboxes.CompareAndDelete(ch, o)
+	}
+}
+
+// A delivery adds the potential of terminal events to their targets
+// on behalf of the goroutine processing a stream.
+type delivery struct {
+	channel ActivationStream
+	outbox  *outbox
+	// emit receives the activation events of neurons on the stream
+	// caused by each delivery, once it is complete, and wake receives
+	// the neurons whose predicted spike changed.
+	emit func(ActivationEvent)
+	wake func(*Neuron)
+	// wakes holds the time of the wake event scheduled for each
+	// neuron, so that superseded ones are skipped.
+	wakes map[*Neuron]time.Time
+}
+
+// scheduleTo returns a delivery for the stream which schedules the
+// activation events of its neurons straight into the queue, passing
+// each activation to the tap first if there is one, along with a wake
+// event for the predicted spike of each neuron which posts one. The
+// wake events already scheduled in the queue are recorded in wakes.
+// The delivery must be closed when processing finishes.
+func scheduleTo(as *ActivationStream, queue Scheduler, wakes map[*Neuron]time.Time, tap func(ActivationEvent)) delivery {
+	d := delivery{channel: *as, outbox: outboxFor(as), wakes: wakes}
+	for !d.outbox.attached() {
+		d.outbox = outboxFor(as)
+	}
+	d.emit = func(ae ActivationEvent) {
+		if tap != nil {
+			tap(ae)
+		}
+		schedule(queue, ae)
+	}
+	d.wake = func(n *Neuron) {
+		next := n.next_spike.Load()
+		if next == nil {
+			return
+		}
+		// A wake event already scheduled no later will reschedule
+		// this one when it is delivered.
+		if t, ok := wakes[n]; ok && !next.Before(t) {
+			return
+		}
+		wakes[n] = *next
+		queue.Push(&TerminalEvent{Time: *next, Neuron: n, ID: n.ID, Label: n.Label, wake: true})
+	}
+	return d
+}
+
+// close returns the stream's neurons to sending their activation
+// events to it.
+func (d delivery) close() {
+	d.outbox.detach(d.channel)
+}
+
+// collect schedules the activation events and wakes in the outbox.
+func (d delivery) collect() {
+	events, wakes := d.outbox.take()
+	for _, ae := range events {
+		d.emit(ae)
+	}
+	for _, n := range wakes {
+		d.wake(n)
+	}
+}
+
+// deliver adds the potential of the terminal event to its targets, or
+// wakes its neuron unless the wake event was superseded, and then
+// schedules the resulting activations.
+func (d delivery) deliver(te *TerminalEvent) {
+	d.outbox.open()
+	if !te.wake {
+		d.signalAxonTerminals(te.Neuron.Axon, te.Delay, te.Time)
+	} else if t, ok := d.wakes[te.Neuron]; ok && t.Equal(te.Time) {
+		delete(d.wakes, te.Neuron)
+		te.Neuron.wake(te.Time)
+	}
+	d.collect()
+}
+
+// signalAxonTerminals adds potential at the given time to each of
+// the axon's terminals with the given synapse delay.
+func (d delivery) signalAxonTerminals(a Axon, delay time.Duration, t time.Time) {
+	if delay == 0 {
+		for _, n := range a.Terminals {
+			n.AddPotentialAt(DEFAULT_WEIGHT, t)
+		}
+	}
+	for _, s := range a.Synapses {
+		if s.Delay == delay {
+			s.arrived(t)
+			s.Target.AddPotentialAt(s.transmit(t), t)
+		}
+	}
+}
+
+// schedule inserts a terminal event into the queue for each distinct
+// synapse delay of the activated neuron's axon.
+func schedule(queue Scheduler, ae ActivationEvent) {
+	axon := ae.Neuron.Axon
+	for _, delay := range axon.synapseDelays() {
+		terminal_event_time := ae.Time.Add(axon.Delay + delay)
+		queue.Push(&TerminalEvent{
+			Time:   terminal_event_time,
+			Neuron: ae.Neuron,
+			Delay:  delay,
+			ID:     ae.ID,
+			Label:  ae.Label,
+		})
 	}
 }
 
 // processQueue checks the provided queue of terminal events
-// processing any which are ready, and returning a timer channel
-// which will receive when the queue should be processed
+// processing any which are ready, and returning a timer
+// which will fire when the queue should be processed
 // next.
-func processQueue(queue *OrderedList) <-chan time.Time {
-	e := queue.Front()
-	now := time.Now()
+func processQueue(queue Scheduler, d delivery, clk clock.Clock) clock.Timer {
+	now := clk.Now()
 	// How can the delta vary runtime?
 	delta := time.Duration(130) * time.Microsecond
 	for {
-		if e == nil {
+		te := queue.Peek()
+		if te == nil {
 			return nil
 		}
-		te := e.Value.(*TerminalEvent)
 		time_until_next := te.Time.Sub(now)
 		if time_until_next > delta {
-			return time.NewTimer(time_until_next - delta).C
+			return clk.NewTimer(time_until_next - delta)
 		}
-		next := e.Next()
-		queue.Remove(e)
-		signalAxonTerminals(te.Neuron.Axon, te.Time)
-		e = next
+		queue.Pop()
+		d.deliver(te)
 	}
-	return nil
+}
+
+// ProcessOptions configure the processing of an activation stream.
+type ProcessOptions struct {
+	// Clock determines when queued terminal events are ready. The
+	// nil value uses the system clock.
+	Clock clock.Clock
+	// StopWhenEmpty returns as soon as the queue is empty, rather
+	// than waiting for the stream to be closed.
+	StopWhenEmpty bool
+	// Scheduler orders the queued terminal events. The nil value
+	// uses a new HeapScheduler.
+	Scheduler Scheduler
+	// Drain delivers the queued terminal events immediately when
+	// ProcessContext is cancelled, rather than discarding them.
+	// Activation events caused by draining are discarded.
+	Drain bool
+	// Tap, if set, is called with each activation event as it is
+	// scheduled, on the goroutine processing the stream, so that the
+	// activity can be recorded without changing the neurons.
+	Tap func(ActivationEvent)
+}
+
+// An UndeliveredError is returned by ProcessContext when its context
+// is done while terminal events are still queued, including those of
+// the activation events still waiting on the stream.
+type UndeliveredError struct {
+	// Undelivered is the number of terminal events discarded.
+	Undelivered int
+	// Err is the context's error.
+	Err error
+}
+
+func (e *UndeliveredError) Error() string {
+	return fmt.Sprintf("%s with %d terminal events undelivered", e.Err, e.Undelivered)
+}
+
+func (e *UndeliveredError) Unwrap() error {
+	return e.Err
 }
 
 // Process() processing the incoming activation events, by
@@ -100,26 +365,110 @@ func processQueue(queue *OrderedList) <-chan time.Time {
 // queue. The function returns after the activation stream
 // is closed and the queue is cleared.
 func (as *ActivationStream) Process() {
-	as.process(false)
+	as.ProcessWithOptions(ProcessOptions{})
 }
 
 func (as *ActivationStream) ProcessUntilEmpty() {
-	as.process(true)
+	as.ProcessWithOptions(ProcessOptions{StopWhenEmpty: true})
+}
+
+// ProcessWithOptions processes the activation stream as Process does,
+// configured by the given options.
+func (as *ActivationStream) ProcessWithOptions(opts ProcessOptions) {
+	as.process(nil, opts)
+}
+
+// ProcessContext processes the activation stream as ProcessWithOptions
+// does, but also returns when the context is done. The queued terminal
+// events, along with those of the activation events waiting on the
+// stream, are then drained or discarded, depending on opts.Drain, and
+// the context's error is returned, as an *UndeliveredError if any
+// were discarded. It returns nil if processing finished first.
+func (as *ActivationStream) ProcessContext(ctx context.Context, opts ProcessOptions) error {
+	undelivered, stopped := as.process(ctx.Done(), opts)
+	if !stopped {
+		return nil
+	}
+	if undelivered > 0 {
+		return &UndeliveredError{Undelivered: undelivered, Err: ctx.Err()}
+	}
+	return ctx.Err()
 }
 
-func (as *ActivationStream) process(stop_when_empty bool) {
-	queue := OrderedList{*list.New()}
+// process processes the activation stream until it is closed and the
+// queue is cleared, until the queue is empty if opts.StopWhenEmpty, or
+// until done is closed. In the last case it reports that it was
+// stopped, and the number of queued events discarded unless
+// opts.Drain.
+func (as *ActivationStream) process(done <-chan struct{}, opts ProcessOptions) (undelivered int, stopped bool) {
+	clk := clock.OrSystem(opts.Clock)
+	queue := opts.Scheduler
+	if queue == nil {
+		queue = new(HeapScheduler)
+	}
+	d := scheduleTo(as, queue, make(map[*Neuron]time.Time), opts.Tap)
+	defer d.close()
 	// A nil timer channel will block initially, until we assign an
 	// timer channel.
+	var timer clock.Timer
 	var timer_ch <-chan time.Time
+	// reschedule processes the queue, replacing the timer for the
+	// next ready event, and reports whether processing is finished.
+	reschedule := func(closed bool) bool {
+		if timer != nil {
+			timer.Stop()
+		}
+		timer, timer_ch = processQueue(queue, d, clk), nil
+		if timer != nil {
+			timer_ch = timer.C()
+		}
+		return timer == nil && (opts.StopWhenEmpty || closed)
+	}
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
 	_as := *as
 	for {
 		select {
+		case <-done:
+			// The activation events waiting on the stream are
+			// scheduled too, so that they are drained or counted.
+			for receiving := true; receiving; {
+				select {
+				case ae, ok := <-_as:
+					if ok {
+						d.emit(ae)
+					} else {
+						_as = nil
+					}
+				default:
+					receiving = false
+				}
+			}
+			if !opts.Drain {
+				// Wake events are not counted, as they carry no
+				// potential.
+				for te := queue.Pop(); te != nil; te = queue.Pop() {
+					if !te.wake {
+						undelivered += 1
+					}
+				}
+				return undelivered, true
+			}
+			// Activation events and wakes caused by draining are
+			// discarded, so that recurrent or spontaneous activity
+			// cannot prevent returning.
+			d.emit, d.wake = func(ActivationEvent) {}, func(*Neuron) {}
+			for te := queue.Pop(); te != nil; te = queue.Pop() {
+				d.deliver(te)
+			}
+			return 0, true
+
 		case ae, ok := <-_as:
 			if ok {
-				terminal_event_time := ae.Time.Add(ae.Neuron.Axon.Delay)
-				te := TerminalEvent{terminal_event_time, ae.Neuron}
-				queue.Insert(&te)
+				d.emit(ae)
 			} else {
 				// No more activation events will be received, but we need to
 				// finish processing the queued events. By switching to a nil
@@ -127,16 +476,19 @@ func (as *ActivationStream) process(stop_when_empty bool) {
 				// queue to be processed.
 				_as = nil
 			}
+			if reschedule(_as == nil) {
+				return 0, false
+			}
 
-			timer_ch = processQueue(&queue)
-			if timer_ch == nil && (stop_when_empty || _as == nil) {
-				return
+		case <-d.outbox.ready:
+			d.collect()
+			if reschedule(_as == nil) {
+				return 0, false
 			}
 
 		case <-timer_ch:
-			timer_ch = processQueue(&queue)
-			if timer_ch == nil && (stop_when_empty || _as == nil) {
-				return
+			if reschedule(_as == nil) {
+				return 0, false
 			}
 		}
 	}
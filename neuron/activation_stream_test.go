@@ -26,8 +26,11 @@
 package neuron
 
 import (
+	"context"
+	"errors"
 	"github.com/absoludity/go-neuron/action_potential"
-	"math"
+	"github.com/absoludity/go-neuron/clock"
+	"runtime"
 	"testing"
 	"time"
 )
@@ -37,12 +40,12 @@ import (
 func makeNeuronWithTerminal(terminal action_potential.ActionPotential,
 	delay time.Duration, as *ActivationStream, ap action_potential.ActionPotential) *Neuron {
 	return &Neuron{
-		Axon{
-			[]action_potential.ActionPotential{terminal},
-			delay,
+		Axon: Axon{
+			Terminals: []action_potential.ActionPotential{terminal},
+			Delay:     delay,
 		},
-		as,
-		ap,
+		ActivationStream: as,
+		ActionPotential:  ap,
 	}
 }
 
@@ -58,7 +61,7 @@ func TestProcess5Simultaneous(t *testing.T) {
 		5 * time.Millisecond,
 	}
 	for _, delay := range delays {
-		as <- ActivationEvent{now, makeNeuronWithTerminal(fake, delay, nil, nil)}
+		as <- ActivationEvent{Time: now, Neuron: makeNeuronWithTerminal(fake, delay, nil, nil)}
 	}
 	close(as)
 
@@ -88,8 +91,8 @@ func TestOrdersAccordingToDelay(t *testing.T) {
 		4 * time.Millisecond,
 		5 * time.Millisecond,
 	}
-	as <- ActivationEvent{now, makeNeuronWithTerminal(fake, 5*time.Millisecond, nil, nil)}
-	as <- ActivationEvent{now, makeNeuronWithTerminal(fake, 1*time.Millisecond, nil, nil)}
+	as <- ActivationEvent{Time: now, Neuron: makeNeuronWithTerminal(fake, 5*time.Millisecond, nil, nil)}
+	as <- ActivationEvent{Time: now, Neuron: makeNeuronWithTerminal(fake, 1*time.Millisecond, nil, nil)}
 	close(as)
 
 	as.Process()
@@ -122,7 +125,7 @@ func TestProcessUntilEmpty(t *testing.T) {
 		5 * time.Millisecond,
 	}
 	for _, delay := range delays {
-		as <- ActivationEvent{now, makeNeuronWithTerminal(fake, delay, nil, nil)}
+		as <- ActivationEvent{Time: now, Neuron: makeNeuronWithTerminal(fake, delay, nil, nil)}
 	}
 
 	as.ProcessUntilEmpty()
@@ -143,35 +146,60 @@ func TestProcessUntilEmpty(t *testing.T) {
 func TestActivationStreamAccuracy(t *testing.T) {
 	// Connect 1000 neurons, each with a different axon delay,
 	// all to the one end-point neuron, so that we can accumulate
-	// the accuracy of when the signals reach the end-point.
+	// the accuracy of when the signals reach the end-point. The
+	// fake clock delivers each signal exactly when the stream's
+	// timer expires, regardless of the load on the machine.
+	fake := clock.NewFake(time.Unix(0, 0))
 	accum := action_potential.NewAccuracyAccumulator(
 		new(action_potential.Simple))
+	accum.Clock = fake
 	activation_stream := make(ActivationStream, 1000)
 	neurons := make([]*Neuron, 1000)
 	// Each neuron has a delay ranging from 10ms to 1009ms
 	for i := 0; i < 1000; i++ {
 		neurons[i] = &Neuron{
-			Axon{
-				[]action_potential.ActionPotential{accum},
-				time.Duration(i+10) * time.Millisecond,
+			Axon: Axon{
+				Terminals: []action_potential.ActionPotential{accum},
+				Delay:     time.Duration(i+10) * time.Millisecond,
 			},
-			&activation_stream,
-			action_potential.NewAlwaysFirer(new(action_potential.Simple)),
+			ActivationStream: &activation_stream,
+			ActionPotential:  action_potential.NewAlwaysFirer(new(action_potential.Simple)),
+			Clock:            fake,
 		}
 	}
-
-	// Fire all 1000 neurons near-simultaneously (note, the difference
-	// in actual firing times won't affect the accuracy which will
-	// be calculated based on when each neuron was fired.)
 	for _, n := range neurons {
 		n.AddPotential(0)
 	}
-	activation_stream.ProcessUntilEmpty()
+	// The terminals are not neurons, so nothing more will be sent.
+	close(activation_stream)
 
-	expected_accuracy := time.Duration(100) * time.Microsecond
-	if accum.AverageDelta*accum.AverageDelta > expected_accuracy*expected_accuracy {
-		t.Errorf("Expected an accuracy better than %s, but "+
-			"average delta was %s.", expected_accuracy, accum.AverageDelta)
+	finished := make(chan struct{})
+	go func() {
+		activation_stream.ProcessWithOptions(ProcessOptions{Clock: fake})
+		close(finished)
+	}()
+	for running := true; running; {
+		select {
+		case <-finished:
+			running = false
+		default:
+			if deadline, ok := fake.NextTimer(); ok {
+				fake.Set(deadline)
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}
+
+	// Terminal events are processed up to 130µs before they are due.
+	snapshot := accum.Snapshot()
+	expected_delta := -time.Duration(130) * time.Microsecond
+	if snapshot.Count != 1000 {
+		t.Errorf("Expected 1000 signals, actual was %d.", snapshot.Count)
+	}
+	if snapshot.Min != expected_delta || snapshot.Max != expected_delta {
+		t.Errorf("Expected every delta to be %s, actual ranged from %s to %s.",
+			expected_delta, snapshot.Min, snapshot.Max)
 	}
 }
 
@@ -180,51 +208,465 @@ func TestActivationStreamDelay(t *testing.T) {
 	// same axon_delay, then we expect the signal to reach the
 	// final neuron exactly 1000*axon_delay later. We can then check
 	// the variance between when the signal was calculated to reach
-	// the end neuron, and when it really did arrive.
+	// the end neuron, and when it really did arrive, with a fake clock
+	// advanced to each timer as it is set, so that the result is exact
+	// regardless of the load on the machine.
+	fake := clock.NewFake(time.Unix(0, 0))
 	activation_stream := make(ActivationStream, 1000)
 	axon_delay := time.Duration(100) * time.Microsecond
 
-	// Create the final end neuron with an event recorder in the
-	// axon terminal.
-	event_recorder := action_potential.NewEventRecorder(
-		new(action_potential.Simple))
+	events := make(chan action_potential.AddPotentialEvent, 1)
+	event_recorder := action_potential.NewStreamingEventRecorder(
+		new(action_potential.Simple), action_potential.ChannelSink(events))
+	event_recorder.Clock = fake
 	always_fire := action_potential.NewAlwaysFirer(new(action_potential.Simple))
 	end := makeNeuronWithTerminal(event_recorder, axon_delay, &activation_stream,
 		always_fire)
 	prev := end
-
-	// String the other 999 neurons together.
 	for i := 0; i < 999; i++ {
 		always_fire = action_potential.NewAlwaysFirer(new(action_potential.Simple))
-		neuron := makeNeuronWithTerminal(prev, axon_delay,
+		prev = makeNeuronWithTerminal(prev, axon_delay,
 			&activation_stream, always_fire)
-		prev = neuron
 	}
 	start := prev
 
-	// Start off the chain reaction then sleep for the expected
-	// duration of the chain of events.
-	started_at := time.Now()
+	started_at := fake.Now()
 	start.AddPotentialAt(0, started_at)
-	go activation_stream.Process()
-	expected_duration := axon_delay * 1000
-	time.Sleep(expected_duration)
-	close(activation_stream)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		activation_stream.process(done, ProcessOptions{Clock: fake})
+		close(finished)
+	}()
 
-	if len(event_recorder.Events) != 1 {
-		t.Errorf("Expected 1 event, got %d.", len(event_recorder.Events))
+	// Advance the clock to each timer until the signal reaches the
+	// end of the chain.
+	var event action_potential.AddPotentialEvent
+	for received := false; !received; {
+		select {
+		case event = <-events:
+			received = true
+		default:
+			if deadline, ok := fake.NextTimer(); ok {
+				fake.Set(deadline)
+			} else {
+				runtime.Gosched()
+			}
+		}
 	}
-	event := event_recorder.Events[0]
-	duration := event.Time.Sub(started_at)
-	if expected_duration != duration {
+	close(done)
+	<-finished
+
+	expected_duration := axon_delay * 1000
+	if duration := event.Time.Sub(started_at); duration != expected_duration {
 		t.Errorf("Expected duration was %s, actual was %s.",
 			expected_duration, duration)
 	}
+	// Terminal events are processed up to 130µs before they are due.
 	variance := event.RealTime.Sub(event.Time)
-	percent_of_expected := float64(variance) / float64(expected_duration) * 100
-	tolerance := 0.5
-	if math.Abs(percent_of_expected) > tolerance {
-		t.Errorf("Actual delay was %f%% of expected delay, which is "+
-			"greater than the %f%% tolerance.", percent_of_expected, tolerance)
+	expected_variance := -time.Duration(130) * time.Microsecond
+	if variance != expected_variance {
+		t.Errorf("Expected variance of %s, actual was %s.",
+			expected_variance, variance)
+	}
+}
+
+func TestProcessContext(t *testing.T) {
+	tests := []struct {
+		drain                bool
+		expected_events      int
+		expected_undelivered int
+	}{
+		{false, 0, 1},
+		{true, 1, 0},
+	}
+	for _, tt := range tests {
+		fake := clock.NewFake(time.Unix(0, 0))
+		activation_stream := make(ActivationStream, 1)
+		recorder := action_potential.NewEventRecorder(new(action_potential.Simple))
+		n := makeNeuronWithTerminal(recorder, time.Hour, &activation_stream,
+			action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+		n.AddPotentialAt(0, fake.Now())
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan error)
+		go func() {
+			result <- activation_stream.ProcessContext(ctx, ProcessOptions{
+				Clock: fake,
+				Drain: tt.drain,
+			})
+		}()
+		// Cancel once the terminal event is queued.
+		for _, ok := fake.NextTimer(); !ok; _, ok = fake.NextTimer() {
+			runtime.Gosched()
+		}
+		cancel()
+		err := <-result
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected %v, actual %v.", context.Canceled, err)
+		}
+		var undelivered *UndeliveredError
+		if errors.As(err, &undelivered) {
+			if undelivered.Undelivered != tt.expected_undelivered {
+				t.Errorf("Expected %d undelivered, actual %d.",
+					tt.expected_undelivered, undelivered.Undelivered)
+			}
+		} else if tt.expected_undelivered != 0 {
+			t.Errorf("Expected an UndeliveredError, actual %v.", err)
+		}
+		if len(recorder.Events) != tt.expected_events {
+			t.Errorf("Expected %d events when drain is %t, actual %d.",
+				tt.expected_events, tt.drain, len(recorder.Events))
+		}
+	}
+}
+
+func TestProcessContextWaitingEvents(t *testing.T) {
+	// An activation event still waiting on the stream when the context
+	// is done is counted as undelivered, or delivered when draining.
+	for _, drain := range []bool{false, true} {
+		fake := clock.NewFake(time.Unix(0, 0))
+		recorder := action_potential.NewEventRecorder(new(action_potential.Simple))
+		n := makeNeuronWithTerminal(recorder, time.Second, nil, nil)
+		activation_stream := make(ActivationStream, 1)
+		activation_stream <- ActivationEvent{Time: fake.Now(), Neuron: n}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := activation_stream.ProcessContext(ctx, ProcessOptions{Clock: fake, Drain: drain})
+
+		var undelivered *UndeliveredError
+		if drain {
+			if err != context.Canceled || len(recorder.Events) != 1 {
+				t.Errorf("Expected the waiting event to be drained, actual %v with %d events.",
+					err, len(recorder.Events))
+			}
+		} else if !errors.As(err, &undelivered) || undelivered.Undelivered != 1 {
+			t.Errorf("Expected 1 undelivered terminal event, actual %v.", err)
+		}
+		if len(activation_stream) != 0 {
+			t.Errorf("Expected the stream to be emptied, actual %d waiting.",
+				len(activation_stream))
+		}
+	}
+}
+
+func TestProcessContextUncountedWakes(t *testing.T) {
+	// A neuron waiting to be woken for its predicted spike has no
+	// terminal event undelivered.
+	fake := clock.NewFake(time.Unix(0, 0))
+	recorder := action_potential.NewEventRecorder(new(action_potential.Simple))
+	activation_stream := make(ActivationStream, 1)
+	n := makeNeuronWithTerminal(recorder, time.Second, nil, nil)
+	activation_stream <- ActivationEvent{Time: fake.Now(), Neuron: n}
+	hh := action_potential.NewHodgkinHuxley(action_potential.SQUID_GIANT_AXON)
+	hh.GetPotentialAt(fake.Now())
+	spontaneous := makeNeuronWithTerminal(recorder, 0, &activation_stream, hh)
+	spontaneous.AddPotentialAt(20, fake.Now())
+	spike, _ := hh.NextSpike()
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- activation_stream.ProcessContext(ctx, ProcessOptions{Clock: fake})
+	}()
+
+	wake_deadline := spike.Add(-130 * time.Microsecond)
+	for deadline, ok := fake.NextTimer(); !ok || !deadline.Equal(wake_deadline); deadline, ok = fake.NextTimer() {
+		runtime.Gosched()
+	}
+	cancel()
+	var err error
+	select {
+	case err = <-result:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected ProcessContext to return once cancelled.")
+	}
+
+	var undelivered *UndeliveredError
+	if !errors.As(err, &undelivered) || undelivered.Undelivered != 1 {
+		t.Errorf("Expected 1 undelivered terminal event, actual %v.", err)
+	}
+}
+
+func TestProcessContextReturnsNilWhenFinished(t *testing.T) {
+	activation_stream := make(ActivationStream)
+	close(activation_stream)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := activation_stream.ProcessContext(ctx, ProcessOptions{}); err != nil {
+		t.Errorf("Expected no error, actual %v.", err)
+	}
+}
+
+func TestProcessRecurrentUnbuffered(t *testing.T) {
+	// Two neurons exciting each other and themselves on an unbuffered
+	// stream must not block the goroutine processing it.
+	fake := clock.NewFake(time.Unix(0, 0))
+	activation_stream := make(ActivationStream)
+	recorders := make([]*action_potential.EventRecorder, 2)
+	neurons := make([]*Neuron, 2)
+	for i := range neurons {
+		recorders[i] = action_potential.NewEventRecorder(
+			action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+		neurons[i] = &Neuron{
+			ActivationStream: &activation_stream,
+			ActionPotential:  recorders[i],
+		}
+	}
+	for _, pre := range neurons {
+		for _, post := range neurons {
+			pre.ConnectTo(post, 1, time.Millisecond)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- activation_stream.ProcessContext(ctx, ProcessOptions{Clock: fake})
+	}()
+
+	neurons[0].AddPotentialAt(0, fake.Now())
+	for i := 0; i < 5; {
+		if deadline, ok := fake.NextTimer(); ok {
+			fake.Set(deadline)
+			i += 1
+		} else {
+			runtime.Gosched()
+		}
+	}
+	cancel()
+	<-result
+
+	// Each round every neuron receives potential from every firing
+	// neuron, doubling the inputs, and the first four rounds were
+	// delivered before the fifth timer was set.
+	for i, r := range recorders {
+		if len(r.Events) < 1+2+4+8 {
+			t.Errorf("Expected neuron %d to keep firing, but it received %d inputs.",
+				i, len(r.Events))
+		}
+	}
+}
+
+func TestProcessRecurrentWrapped(t *testing.T) {
+	// A neuron wrapped by a Synchronized which excites itself on an
+	// unbuffered stream must not block the goroutine processing it.
+	fake := clock.NewFake(time.Unix(0, 0))
+	activation_stream := make(ActivationStream)
+	recorder := action_potential.NewEventRecorder(
+		action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+	n := &Neuron{
+		ActivationStream: &activation_stream,
+		ActionPotential:  recorder,
+	}
+	n.Axon.ConnectWithDelay(action_potential.NewSynchronized(n), 1, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- activation_stream.ProcessContext(ctx, ProcessOptions{Clock: fake})
+	}()
+
+	n.AddPotentialAt(0, fake.Now())
+	for i := 0; i < 5; {
+		if deadline, ok := fake.NextTimer(); ok {
+			fake.Set(deadline)
+			i += 1
+		} else {
+			runtime.Gosched()
+		}
+	}
+	cancel()
+	select {
+	case <-result:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected ProcessContext to return once cancelled.")
+	}
+
+	if len(recorder.Events) < 5 {
+		t.Errorf("Expected the neuron to keep firing, but it received %d inputs.",
+			len(recorder.Events))
+	}
+}
+
+func TestProcessRecurrentAliasedStream(t *testing.T) {
+	// A neuron holding its own pointer to the processed stream's
+	// channel, which excites itself, must not block the goroutine
+	// processing it either.
+	fake := clock.NewFake(time.Unix(0, 0))
+	activation_stream := make(ActivationStream)
+	alias := activation_stream
+	recorder := action_potential.NewEventRecorder(
+		action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+	n := &Neuron{
+		ActivationStream: &alias,
+		ActionPotential:  recorder,
+	}
+	n.ConnectTo(n, 1, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- activation_stream.ProcessContext(ctx, ProcessOptions{Clock: fake})
+	}()
+
+	n.AddPotentialAt(0, fake.Now())
+	for i := 0; i < 5; {
+		if deadline, ok := fake.NextTimer(); ok {
+			fake.Set(deadline)
+			i += 1
+		} else {
+			runtime.Gosched()
+		}
+	}
+	cancel()
+	select {
+	case <-result:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected ProcessContext to return once cancelled.")
+	}
+
+	if len(recorder.Events) < 5 {
+		t.Errorf("Expected the neuron to keep firing, but it received %d inputs.",
+			len(recorder.Events))
+	}
+}
+
+func TestProcessSpontaneousSpike(t *testing.T) {
+	// Input added while the stream is processed, which fires a
+	// Hodgkin-Huxley neuron later, wakes it at the predicted spike
+	// without sending anything to the unbuffered stream.
+	fake := clock.NewFake(time.Unix(0, 0))
+	activation_stream := make(ActivationStream)
+	recorder := action_potential.NewEventRecorder(new(action_potential.Simple))
+	hh := action_potential.NewHodgkinHuxley(action_potential.SQUID_GIANT_AXON)
+	hh.GetPotentialAt(fake.Now())
+	n := makeNeuronWithTerminal(recorder, time.Millisecond, &activation_stream, hh)
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- activation_stream.ProcessContext(ctx, ProcessOptions{Clock: fake})
+	}()
+
+	n.AddPotentialAt(20, fake.Now())
+	for i := 0; i < 2; {
+		if deadline, ok := fake.NextTimer(); ok {
+			fake.Set(deadline)
+			i += 1
+		} else {
+			runtime.Gosched()
+		}
+	}
+	cancel()
+	select {
+	case <-result:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected ProcessContext to return once cancelled.")
+	}
+
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Expected 1 event, got %d.", len(recorder.Events))
+	}
+	expected_time := hh.LastSpike().Add(time.Millisecond)
+	if recorder.Events[0].Time != expected_time {
+		t.Errorf("Expected event at %s, got %s.", expected_time, recorder.Events[0].Time)
+	}
+}
+
+func TestProcessWeightedSynapses(t *testing.T) {
+	as := make(ActivationStream, 1)
+	now := time.Now()
+	terminal := action_potential.NewEventRecorder(new(action_potential.Simple))
+	excitatory := action_potential.NewEventRecorder(new(action_potential.Simple))
+	inhibitory := action_potential.NewEventRecorder(new(action_potential.Simple))
+	n := makeNeuronWithTerminal(terminal, time.Millisecond, nil, nil)
+	n.Axon.Connect(excitatory, 3)
+	n.Axon.Connect(inhibitory, -2)
+	as <- ActivationEvent{Time: now, Neuron: n}
+	close(as)
+
+	as.Process()
+
+	cases := []struct {
+		recorder *action_potential.EventRecorder
+		weight   action_potential.Potential
+	}{
+		{terminal, DEFAULT_WEIGHT},
+		{excitatory, 3},
+		{inhibitory, -2},
+	}
+	for i, tt := range cases {
+		if len(tt.recorder.Events) != 1 {
+			t.Fatalf("%d: Expected 1 call to AddPotential, received %d.",
+				i, len(tt.recorder.Events))
+		}
+		e := tt.recorder.Events[0]
+		if e.Potential != tt.weight {
+			t.Errorf("%d: Expected potential %.1f, got %.1f.", i, tt.weight, e.Potential)
+		}
+		if e.Time != now.Add(time.Millisecond) {
+			t.Errorf("%d: Expected potential added at %s, got %s.",
+				i, now.Add(time.Millisecond), e.Time)
+		}
+	}
+}
+
+func TestProcessPerSynapseDelays(t *testing.T) {
+	as := make(ActivationStream, 1)
+	now := time.Now()
+	near := action_potential.NewEventRecorder(new(action_potential.Simple))
+	far := action_potential.NewEventRecorder(new(action_potential.Simple))
+	terminal := action_potential.NewEventRecorder(new(action_potential.Simple))
+	n := makeNeuronWithTerminal(terminal, time.Millisecond, nil, nil)
+	n.Axon.ConnectWithDelay(far, 1, 4*time.Millisecond)
+	n.Axon.ConnectWithDelay(near, 1, 2*time.Millisecond)
+	n.Axon.ConnectWithDelay(far, 2, 4*time.Millisecond)
+	as <- ActivationEvent{Time: now, Neuron: n}
+	close(as)
+
+	as.Process()
+
+	cases := []struct {
+		recorder *action_potential.EventRecorder
+		delay    time.Duration
+		count    int
+	}{
+		{terminal, time.Millisecond, 1},
+		{near, 3 * time.Millisecond, 1},
+		{far, 5 * time.Millisecond, 2},
+	}
+	for i, tt := range cases {
+		if len(tt.recorder.Events) != tt.count {
+			t.Fatalf("%d: Expected %d calls to AddPotential, received %d.",
+				i, tt.count, len(tt.recorder.Events))
+		}
+		for _, e := range tt.recorder.Events {
+			if e.Time != now.Add(tt.delay) {
+				t.Errorf("%d: Expected potential added at %s, got %s.",
+					i, now.Add(tt.delay), e.Time)
+			}
+		}
+	}
+}
+
+func TestSynapseDelays(t *testing.T) {
+	var a Axon
+	if len(a.synapseDelays()) != 0 {
+		t.Errorf("Expected no delays for an unconnected axon, got %v.",
+			a.synapseDelays())
+	}
+	a.ConnectWithDelay(nil, 1, 3*time.Millisecond)
+	a.ConnectWithDelay(nil, 1, time.Millisecond)
+	a.ConnectWithDelay(nil, 1, 3*time.Millisecond)
+	a.Terminals = []action_potential.ActionPotential{nil}
+
+	delays := a.synapseDelays()
+
+	expected := []time.Duration{0, time.Millisecond, 3 * time.Millisecond}
+	if len(delays) != len(expected) {
+		t.Fatalf("Expected delays %v, got %v.", expected, delays)
+	}
+	for i := range expected {
+		if delays[i] != expected[i] {
+			t.Errorf("Expected delays %v, got %v.", expected, delays)
+		}
 	}
 }
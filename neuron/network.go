@@ -0,0 +1,172 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"context"
+	"fmt"
+	"github.com/absoludity/go-neuron/action_potential"
+	"github.com/absoludity/go-neuron/clock"
+	"time"
+)
+
+// A Network owns a set of neurons and the activation stream they
+// share, so that networks can be built without wiring the stream and
+// synapses by hand.
+type Network struct {
+	// Clock is used by Run and by AddPotential on the network's
+	// neurons, including those added before it is set. The nil value
+	// uses the system clock.
+	Clock clock.Clock
+	// Tap, if set, is called with each activation event as it is
+	// scheduled by Run or the network's Simulation.
+	Tap func(ActivationEvent)
+
+	stream      ActivationStream
+	registry    Registry
+	populations []population
+	simulation  *Simulation
+}
+
+// A population is a named group of neurons added together.
+type population struct {
+	name string
+	ids  []NeuronID
+}
+
+// NewNetwork returns an empty network whose activation stream can
+// buffer the given number of activation events.
+func NewNetwork(buffer int) *Network {
+	return &Network{stream: make(ActivationStream, buffer)}
+}
+
+// AddNeuron adds a neuron with the given model to the network,
+// returning its ID.
+func (net *Network) AddNeuron(model action_potential.ActionPotential) NeuronID {
+	n := &Neuron{ActivationStream: &net.stream, ActionPotential: model, Clock: networkClock{net}}
+	// A new neuron without a label cannot fail to register.
+	id, _ := net.registry.Register(n)
+	return id
+}
+
+// networkClock is the Clock of a network's neurons, which uses the
+// network's Clock when called, so that it can be set after the
+// neurons are added.
+type networkClock struct {
+	net *Network
+}
+
+func (c networkClock) Now() time.Time {
+	return clock.OrSystem(c.net.Clock).Now()
+}
+
+func (c networkClock) NewTimer(d time.Duration) clock.Timer {
+	return clock.OrSystem(c.net.Clock).NewTimer(d)
+}
+
+// AddPopulation adds a named group of neurons to the network, each
+// with an action potential returned by model, and returns their IDs.
+// Names are not checked for uniqueness; Population returns the first
+// population with a name.
+func (net *Network) AddPopulation(name string, size int, model func() action_potential.ActionPotential) []NeuronID {
+	ids := make([]NeuronID, 0, size)
+	for i := 0; i < size; i++ {
+		ids = append(ids, net.AddNeuron(model()))
+	}
+	net.populations = append(net.populations, population{name, ids})
+	return ids
+}
+
+// Population returns the IDs of the neurons in the named population,
+// or nil if there is no such population.
+func (net *Network) Population(name string) []NeuronID {
+	for _, p := range net.populations {
+		if p.name == name {
+			return p.ids
+		}
+	}
+	return nil
+}
+
+// Neuron returns the neuron with the given ID, or nil if there is
+// no such neuron in the network.
+func (net *Network) Neuron(id NeuronID) *Neuron {
+	return net.registry.Neuron(id)
+}
+
+// ID returns the ID of the given neuron, and whether it belongs to
+// the network.
+func (net *Network) ID(n *Neuron) (NeuronID, bool) {
+	return n.ID, net.registry.contains(n)
+}
+
+// Len returns the number of neurons in the network.
+func (net *Network) Len() int {
+	return net.registry.Len()
+}
+
+// Registry returns the registry of the network's neurons, for looking
+// them up by label.
+func (net *Network) Registry() *Registry {
+	return &net.registry
+}
+
+// Stream returns the activation stream shared by the network's
+// neurons.
+func (net *Network) Stream() *ActivationStream {
+	return &net.stream
+}
+
+// Connect adds a synapse from the pre-synaptic neuron to the
+// post-synaptic neuron with the given weight and delay.
+func (net *Network) Connect(pre, post NeuronID, weight action_potential.Potential, delay time.Duration) (*Synapse, error) {
+	pre_neuron, post_neuron := net.Neuron(pre), net.Neuron(post)
+	if pre_neuron == nil {
+		return nil, fmt.Errorf("unknown pre-synaptic neuron %d", pre)
+	}
+	if post_neuron == nil {
+		return nil, fmt.Errorf("unknown post-synaptic neuron %d", post)
+	}
+	return pre_neuron.ConnectTo(post_neuron, weight, delay), nil
+}
+
+// Simulation returns the network's simulation, for running it in
+// virtual time rather than with Run.
+func (net *Network) Simulation() *Simulation {
+	if net.simulation == nil {
+		net.simulation = NewSimulation(&net.stream)
+		net.simulation.Tap = net.Tap
+	}
+	return net.simulation
+}
+
+// Run processes the network's activation stream until the context is
+// done, returning the context's error, as an *UndeliveredError if
+// activation events had not yet reached their terminals. Those events
+// are dropped.
+func (net *Network) Run(ctx context.Context) error {
+	return net.stream.ProcessContext(ctx, ProcessOptions{Clock: net.Clock, Tap: net.Tap})
+}
@@ -0,0 +1,117 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"context"
+	"github.com/absoludity/go-neuron/action_potential"
+	"github.com/absoludity/go-neuron/clock"
+	"testing"
+	"time"
+)
+
+func TestNetworkRun(t *testing.T) {
+	net := NewNetwork(10)
+	pre := net.AddNeuron(action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+	recorder := action_potential.NewEventRecorder(new(action_potential.Simple))
+	post := net.AddNeuron(recorder)
+	if _, err := net.Connect(pre, post, 3, time.Millisecond); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	now := time.Now()
+
+	net.Neuron(pre).AddPotentialAt(0, now)
+	err := net.Run(ctx)
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected %v, got %v.", context.DeadlineExceeded, err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Expected 1 call to AddPotential, received %d.", len(recorder.Events))
+	}
+	e := recorder.Events[0]
+	if e.Potential != 3 || e.Time != now.Add(time.Millisecond) {
+		t.Errorf("Expected 3.0 added at %s, got %.1f at %s.",
+			now.Add(time.Millisecond), e.Potential, e.Time)
+	}
+}
+
+func TestNetworkClockSetAfterAddNeuron(t *testing.T) {
+	net := NewNetwork(1)
+	id := net.AddNeuron(action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+	fake := clock.NewFake(time.Unix(5, 0))
+	net.Clock = fake
+
+	net.Neuron(id).AddPotential(1)
+
+	if ae := <-net.stream; ae.Time != fake.Now() {
+		t.Errorf("Expected the event at %s, got %s.", fake.Now(), ae.Time)
+	}
+}
+
+func TestNetworkIDs(t *testing.T) {
+	net := NewNetwork(1)
+	ids := []NeuronID{
+		net.AddNeuron(new(action_potential.Simple)),
+		net.AddNeuron(new(action_potential.Simple)),
+	}
+
+	if net.Len() != 2 {
+		t.Errorf("Expected 2 neurons, got %d.", net.Len())
+	}
+	for i, id := range ids {
+		if id != NeuronID(i+1) {
+			t.Errorf("Expected ID %d, got %d.", i+1, id)
+		}
+		n := net.Neuron(id)
+		if n == nil || n.ActivationStream != net.Stream() {
+			t.Fatalf("Expected neuron %d on the network's stream.", id)
+		}
+		if actual, ok := net.ID(n); !ok || actual != id {
+			t.Errorf("Expected ID %d for neuron, got %d (%t).", id, actual, ok)
+		}
+	}
+	if net.Neuron(3) != nil || net.Neuron(0) != nil {
+		t.Error("Expected no neuron for unknown IDs.")
+	}
+	if _, ok := net.ID(&Neuron{}); ok {
+		t.Error("Expected no ID for a neuron outside the network.")
+	}
+}
+
+func TestNetworkConnectUnknown(t *testing.T) {
+	net := NewNetwork(1)
+	id := net.AddNeuron(new(action_potential.Simple))
+
+	if _, err := net.Connect(id, 5, 1, 0); err == nil {
+		t.Error("Expected an error connecting to an unknown neuron.")
+	}
+	if _, err := net.Connect(5, id, 1, 0); err == nil {
+		t.Error("Expected an error connecting from an unknown neuron.")
+	}
+}
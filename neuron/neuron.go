@@ -35,15 +35,19 @@ package neuron
 
 import (
 	"github.com/absoludity/go-neuron/action_potential"
+	"github.com/absoludity/go-neuron/clock"
+	"sync/atomic"
 	"time"
 )
 
 // An Axon can have many terminals connecting to other neurons and
 // an associated delay between the neurons activation and when the
-// signal reaches the terminals.
+// signal reaches the terminals. Terminals receive DEFAULT_WEIGHT,
+// while Synapses each have their own weight.
 type Axon struct {
 	Terminals []action_potential.ActionPotential
 	Delay     time.Duration
+	Synapses  []*Synapse
 }
 
 // A neuron itself is an ActionPotential implementation,
@@ -53,6 +57,26 @@ type Neuron struct {
 	Axon             Axon
 	ActivationStream *ActivationStream
 	action_potential.ActionPotential
+	// Clock provides the time for AddPotential. The nil value uses
+	// the system clock.
+	Clock clock.Clock
+	// ID identifies the neuron within its Registry, and Label
+	// optionally names it. Both are carried on its events. The
+	// Label may be set before the neuron is registered, or
+	// afterwards with Registry.SetLabel.
+	ID    NeuronID
+	Label string
+	// registry is the Registry which assigned the ID, if any.
+	registry *Registry
+
+	// The synapses connected to this neuron with ConnectTo, and the
+	// time at which it last fired, for plasticity rules.
+	incoming   []*Synapse
+	last_fired time.Time
+	// The time at which a Spontaneous action potential is next
+	// predicted to fire, if any, which is updated by concurrent
+	// writers when the action potential is Synchronized.
+	next_spike atomic.Pointer[time.Time]
 }
 
 // AddPotentialAt updates the default implementation provided by
@@ -61,11 +85,83 @@ type Neuron struct {
 func (n *Neuron) AddPotentialAt(p action_potential.Potential, t time.Time) (action_potential.Potential, bool) {
 	potential, fired := n.ActionPotential.AddPotentialAt(p, t)
 	if fired {
-		*n.ActivationStream <- ActivationEvent{t, n}
+		fired_at := n.firedAt(t)
+		n.last_fired = fired_at
+		for _, s := range n.incoming {
+			s.postSynapticFired(fired_at)
+		}
+		n.send(ActivationEvent{Time: fired_at, Neuron: n, ID: n.ID, Label: n.Label})
 	}
+	n.predict()
 	return potential, fired
 }
 
+// firedAt returns the time at which the action potential fired when
+// potential added at the given time reported firing, which differs if
+// it is Retimed.
+func (n *Neuron) firedAt(t time.Time) time.Time {
+	if r, ok := n.ActionPotential.(action_potential.Retimed); ok {
+		if fired_at := r.FiredAt(); !fired_at.IsZero() {
+			return fired_at
+		}
+	}
+	return t
+}
+
+// predict asks the goroutine processing the stream to wake the neuron
+// when its action potential next fires without further potential, if
+// it is Spontaneous and the prediction has changed.
+func (n *Neuron) predict() {
+	sp, ok := n.ActionPotential.(action_potential.Spontaneous)
+	if !ok || n.ActivationStream == nil {
+		return
+	}
+	t, ok := sp.NextSpike()
+	if !ok {
+		n.next_spike.Store(nil)
+		return
+	}
+	if next := n.next_spike.Load(); next != nil && next.Equal(t) {
+		return
+	}
+	n.next_spike.Store(&t)
+	postWake(n)
+}
+
+// wake adds no potential at the given time, so that the action
+// potential reports its predicted spike, or asks to be woken again if
+// the prediction has since changed.
+func (n *Neuron) wake(t time.Time) {
+	next := n.next_spike.Load()
+	if next == nil {
+		return
+	}
+	if next.Equal(t) {
+		n.AddPotentialAt(0, t)
+		return
+	}
+	postWake(n)
+}
+
+// send communicates the activation event to the stream, or to its
+// outbox if the stream's terminal events are being delivered.
+func (n *Neuron) send(ae ActivationEvent) {
+	if !post(n.ActivationStream, ae) {
+		*n.ActivationStream <- ae
+	}
+}
+
 func (n *Neuron) AddPotential(p action_potential.Potential) (action_potential.Potential, bool) {
-	return n.AddPotentialAt(p, time.Now())
+	return n.AddPotentialAt(p, clock.OrSystem(n.Clock).Now())
+}
+
+// ConnectTo adds a synapse from the neuron's axon to the post-synaptic
+// neuron, with the given weight and delay beyond the axon's own. The
+// post-synaptic neuron keeps track of the synapse, so that its
+// Plasticity can learn from the timing of both neurons.
+func (n *Neuron) ConnectTo(post *Neuron, weight action_potential.Potential, delay time.Duration) *Synapse {
+	s := n.Axon.ConnectWithDelay(post, weight, delay)
+	s.post = post
+	post.incoming = append(post.incoming, s)
+	return s
 }
@@ -27,6 +27,7 @@ package neuron
 
 import (
 	"github.com/absoludity/go-neuron/action_potential"
+	"sync"
 	"testing"
 	"time"
 )
@@ -36,7 +37,7 @@ func TestNeuronFire(t *testing.T) {
 	cb := new(action_potential.Simple)
 	cb.AddPotentialAt(1, now)
 	as := make(ActivationStream, 1)
-	n := &Neuron{Axon{}, &as, cb}
+	n := &Neuron{ActivationStream: &as, ActionPotential: cb}
 	at := now.Add(time.Microsecond * 5)
 
 	actual_potential, fired := n.AddPotentialAt(action_potential.THRESHOLD_POTENTIAL, at)
@@ -66,7 +67,7 @@ func TestNeuronNoFire(t *testing.T) {
 	ap := new(action_potential.Simple)
 	ap.AddPotentialAt(1, now)
 	as := make(ActivationStream, 1)
-	n := &Neuron{Axon{}, &as, ap}
+	n := &Neuron{ActivationStream: &as, ActionPotential: ap}
 	at := now.Add(time.Microsecond * 5)
 
 	actual_potential, fired := n.AddPotentialAt(5, at)
@@ -94,7 +95,7 @@ func TestNeuronFireAddPotential(t *testing.T) {
 	cb := new(action_potential.Simple)
 	cb.AddPotentialAt(1, now)
 	as := make(ActivationStream, 1)
-	n := &Neuron{Axon{}, &as, cb}
+	n := &Neuron{ActivationStream: &as, ActionPotential: cb}
 
 	actual_potential, fired := n.AddPotential(action_potential.THRESHOLD_POTENTIAL)
 
@@ -114,3 +115,102 @@ func TestNeuronFireAddPotential(t *testing.T) {
 			n, ae.Neuron)
 	}
 }
+
+func TestNeuronConcurrentAddPotential(t *testing.T) {
+	// Run with -race to check that a Synchronized action potential
+	// protects the state shared by concurrent writers.
+	writers := 10
+	additions := 100
+	recorder := action_potential.NewEventRecorder(new(action_potential.Simple))
+	as := make(ActivationStream, writers*additions)
+	n := &Neuron{ActivationStream: &as, ActionPotential: action_potential.NewSynchronized(recorder)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < additions; j++ {
+				n.AddPotential(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(recorder.Events) != writers*additions {
+		t.Errorf("Expected %d events, received %d.",
+			writers*additions, len(recorder.Events))
+	}
+	fired := len(as)
+	close(as)
+	for ae := range as {
+		if ae.Neuron != n {
+			t.Error("Received activation event for unexpected neuron.")
+		}
+	}
+	if fired == 0 {
+		t.Error("Expected the accumulated potential to fire the neuron.")
+	}
+}
+
+func TestNeuronSpontaneousWithoutProcessor(t *testing.T) {
+	// Input which will later fire a Spontaneous action potential is
+	// accepted without a stream, and without blocking on an unbuffered
+	// stream which nothing is processing.
+	unbuffered := make(ActivationStream)
+	test_cases := []struct {
+		description string
+		stream      *ActivationStream
+	}{
+		{"no stream", nil},
+		{"unbuffered stream", &unbuffered},
+	}
+
+	for _, tc := range test_cases {
+		start := time.Unix(0, 0)
+		hh := action_potential.NewHodgkinHuxley(action_potential.SQUID_GIANT_AXON)
+		hh.GetPotentialAt(start)
+		n := &Neuron{ActivationStream: tc.stream, ActionPotential: hh}
+
+		added := make(chan bool)
+		go func() {
+			_, fired := n.AddPotentialAt(20, start)
+			added <- fired
+		}()
+
+		select {
+		case fired := <-added:
+			if fired {
+				t.Errorf("%s: Expected the input not to fire yet.", tc.description)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: Expected AddPotentialAt to return.", tc.description)
+		}
+	}
+}
+
+func TestNeuronFiredByLateInput(t *testing.T) {
+	// A late input which causes a later input to fire sends the event
+	// at the time of the later input, and records it for plasticity.
+	now := time.Now()
+	h, err := action_potential.NewHistory(action_potential.DEFAULT_SIMPLE_PARAMS,
+		5*time.Millisecond, action_potential.REEVALUATE)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	as := make(ActivationStream, 1)
+	n := &Neuron{ActivationStream: &as, ActionPotential: action_potential.NewSynchronized(h)}
+
+	n.AddPotentialAt(10, now.Add(2*time.Millisecond))
+	_, fired := n.AddPotentialAt(10, now.Add(time.Millisecond))
+
+	if !fired || len(as) != 1 {
+		t.Fatalf("Expected the late input to fire once, fired %t with %d events.", fired, len(as))
+	}
+	if ae := <-as; ae.Time != now.Add(2*time.Millisecond) {
+		t.Errorf("Expected the event at %s, actual %s.", now.Add(2*time.Millisecond), ae.Time)
+	}
+	if n.last_fired != now.Add(2*time.Millisecond) {
+		t.Errorf("Expected last fired at %s, actual %s.", now.Add(2*time.Millisecond), n.last_fired)
+	}
+}
@@ -0,0 +1,220 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"github.com/absoludity/go-neuron/action_potential"
+	"io"
+	"strconv"
+	"time"
+)
+
+// SPIKES_FORMAT identifies the binary spike format, and is followed
+// by a byte with action_potential.ENCODING_VERSION.
+const SPIKES_FORMAT = "go-neuron/spikes"
+
+// A Spike records the activation of a neuron, as saved in a raster.
+// Times are saved in nanoseconds since the Unix epoch, so must be
+// between the years 1678 and 2262.
+type Spike struct {
+	ID    NeuronID
+	Label string
+	Time  time.Time
+}
+
+func spikeOf(ae ActivationEvent) Spike {
+	return Spike{ae.ID, ae.Label, ae.Time}
+}
+
+// A CSVSpikeWriter saves spikes as CSV, with a header row followed by
+// a row for each spike of its neuron's ID, label and time in
+// nanoseconds since the Unix epoch.
+type CSVSpikeWriter struct {
+	writer         *csv.Writer
+	header_written bool
+	err            error
+}
+
+func NewCSVSpikeWriter(w io.Writer) *CSVSpikeWriter {
+	return &CSVSpikeWriter{writer: csv.NewWriter(w)}
+}
+
+// WriteSpike saves the spike, preceded by the header if it is the
+// first.
+func (sw *CSVSpikeWriter) WriteSpike(s Spike) error {
+	if !sw.header_written {
+		if err := sw.writer.Write([]string{"id", "label", "time"}); err != nil {
+			return err
+		}
+		sw.header_written = true
+	}
+	return sw.writer.Write([]string{
+		strconv.Itoa(int(s.ID)),
+		s.Label,
+		strconv.FormatInt(s.Time.UnixNano(), 10),
+	})
+}
+
+// Tap saves the spike of an activation event, for use as the Tap of
+// ProcessOptions, a Simulation or a Network. The first error is
+// returned by Flush.
+func (sw *CSVSpikeWriter) Tap(ae ActivationEvent) {
+	if sw.err == nil {
+		sw.err = sw.WriteSpike(spikeOf(ae))
+	}
+}
+
+// Flush writes any buffered spikes, returning the first error from
+// Tap or the underlying writer.
+func (sw *CSVSpikeWriter) Flush() error {
+	sw.writer.Flush()
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.writer.Error()
+}
+
+// ReadCSVSpikes loads the spikes saved by a CSVSpikeWriter.
+func ReadCSVSpikes(r io.Reader) ([]Spike, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	records, err := reader.ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	if header := records[0]; header[0] != "id" || header[1] != "label" || header[2] != "time" {
+		return nil, fmt.Errorf("unexpected header %q", header)
+	}
+	var spikes []Spike
+	for i, record := range records[1:] {
+		id, err := strconv.Atoi(record[0])
+		if err != nil {
+			return spikes, fmt.Errorf("row %d: invalid id %q", i+1, record[0])
+		}
+		nanoseconds, err := strconv.ParseInt(record[2], 10, 64)
+		if err != nil {
+			return spikes, fmt.Errorf("row %d: invalid time %q", i+1, record[2])
+		}
+		spikes = append(spikes, Spike{NeuronID(id), record[1], time.Unix(0, nanoseconds)})
+	}
+	return spikes, nil
+}
+
+// A BinarySpikeWriter saves spikes compactly, as the SPIKES_FORMAT
+// header followed by the differences between the ID and time of each
+// spike and those of the previous one, as signed varints. Spikes
+// close together in time therefore take only a few bytes each. Labels
+// are not saved, but can be found from the IDs with a Registry.
+type BinarySpikeWriter struct {
+	writer         *bufio.Writer
+	header_written bool
+	// The ID and time in nanoseconds of the previous spike.
+	previous_id   int64
+	previous_time int64
+	err           error
+}
+
+func NewBinarySpikeWriter(w io.Writer) *BinarySpikeWriter {
+	return &BinarySpikeWriter{writer: bufio.NewWriter(w)}
+}
+
+// WriteSpike saves the spike, preceded by the header if it is the
+// first.
+func (sw *BinarySpikeWriter) WriteSpike(s Spike) error {
+	if !sw.header_written {
+		sw.writer.WriteString(SPIKES_FORMAT)
+		if err := sw.writer.WriteByte(action_potential.ENCODING_VERSION); err != nil {
+			return err
+		}
+		sw.header_written = true
+	}
+	id, t := int64(s.ID), s.Time.UnixNano()
+	var buf [2 * binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], id-sw.previous_id)
+	n += binary.PutVarint(buf[n:], t-sw.previous_time)
+	sw.previous_id, sw.previous_time = id, t
+	_, err := sw.writer.Write(buf[:n])
+	return err
+}
+
+// Tap saves the spike of an activation event, for use as the Tap of
+// ProcessOptions, a Simulation or a Network. The first error is
+// returned by Flush.
+func (sw *BinarySpikeWriter) Tap(ae ActivationEvent) {
+	if sw.err == nil {
+		sw.err = sw.WriteSpike(spikeOf(ae))
+	}
+}
+
+// Flush writes any buffered spikes, returning the first error from
+// Tap or the underlying writer.
+func (sw *BinarySpikeWriter) Flush() error {
+	if err := sw.writer.Flush(); sw.err == nil {
+		return err
+	}
+	return sw.err
+}
+
+// ReadBinarySpikes loads the spikes saved by a BinarySpikeWriter.
+func ReadBinarySpikes(r io.Reader) ([]Spike, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, len(SPIKES_FORMAT)+1)
+	if n, err := io.ReadFull(reader, header); n == 0 && err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if string(header[:len(SPIKES_FORMAT)]) != SPIKES_FORMAT {
+		return nil, fmt.Errorf("unknown spike format %q", header[:len(SPIKES_FORMAT)])
+	}
+	if version := header[len(SPIKES_FORMAT)]; version != action_potential.ENCODING_VERSION {
+		return nil, fmt.Errorf("unsupported encoding version %d (expected %d)",
+			version, action_potential.ENCODING_VERSION)
+	}
+
+	var spikes []Spike
+	var id, t int64
+	for {
+		id_delta, err := binary.ReadVarint(reader)
+		if err == io.EOF {
+			return spikes, nil
+		} else if err != nil {
+			return spikes, err
+		}
+		time_delta, err := binary.ReadVarint(reader)
+		if err == io.EOF {
+			return spikes, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return spikes, err
+		}
+		id, t = id+id_delta, t+time_delta
+		spikes = append(spikes, Spike{ID: NeuronID(id), Time: time.Unix(0, t)})
+	}
+}
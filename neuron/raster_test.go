@@ -0,0 +1,227 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"bytes"
+	"github.com/absoludity/go-neuron/action_potential"
+	"strings"
+	"testing"
+	"time"
+)
+
+var exampleSpikes = []Spike{
+	{3, "hub", time.Unix(10, 500)},
+	{1, "", time.Unix(10, 2500)},
+	// Spikes need not be in order of ID or time.
+	{2, "", time.Unix(9, 0)},
+	{2, "", time.Unix(9, 0)},
+}
+
+// checkSpikes reports differences between the expected and actual
+// spikes, ignoring labels unless with_labels.
+func checkSpikes(t *testing.T, name string, expected, actual []Spike, with_labels bool) {
+	if len(actual) != len(expected) {
+		t.Fatalf("%s: Expected %d spikes, actual %d.", name, len(expected), len(actual))
+	}
+	for i, s := range actual {
+		e := expected[i]
+		if s.ID != e.ID || !s.Time.Equal(e.Time) || (with_labels && s.Label != e.Label) {
+			t.Errorf("%s: Expected spike %v, actual %v.", name, e, s)
+		}
+	}
+}
+
+func TestCSVSpikes(t *testing.T) {
+	var buf bytes.Buffer
+	sw := NewCSVSpikeWriter(&buf)
+	for _, s := range exampleSpikes {
+		if err := sw.WriteSpike(s); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "id,label,time\n3,hub,10000000500\n") {
+		t.Errorf("Expected CSV rows of id, label and time, actual:\n%s", buf.String())
+	}
+	spikes, err := ReadCSVSpikes(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	checkSpikes(t, "CSV", exampleSpikes, spikes, true)
+}
+
+func TestBinarySpikes(t *testing.T) {
+	var buf bytes.Buffer
+	sw := NewBinarySpikeWriter(&buf)
+	for _, s := range exampleSpikes {
+		if err := sw.WriteSpike(s); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	spikes, err := ReadBinarySpikes(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	checkSpikes(t, "binary", exampleSpikes, spikes, false)
+}
+
+func TestBinarySpikesAreCompact(t *testing.T) {
+	// A thousand neurons firing in turn, a millisecond apart, take a
+	// byte for each ID and three for each time.
+	var buf bytes.Buffer
+	sw := NewBinarySpikeWriter(&buf)
+	start := time.Unix(1000, 0)
+	for i := 0; i < 1000; i++ {
+		sw.WriteSpike(Spike{ID: NeuronID(i), Time: start.Add(time.Duration(i) * time.Millisecond)})
+	}
+	sw.Flush()
+
+	header_size := len(SPIKES_FORMAT) + 1
+	// The first spike's time is relative to the Unix epoch, taking
+	// six bytes.
+	first_size := 1 + 6
+	if expected := header_size + first_size + 999*4; buf.Len() > expected {
+		t.Errorf("Expected at most %d bytes, actual %d.", expected, buf.Len())
+	}
+}
+
+func TestReadSpikesEmpty(t *testing.T) {
+	if spikes, err := ReadCSVSpikes(strings.NewReader("")); spikes != nil || err != nil {
+		t.Errorf("Expected no CSV spikes, actual %v (%v).", spikes, err)
+	}
+	if spikes, err := ReadBinarySpikes(strings.NewReader("")); spikes != nil || err != nil {
+		t.Errorf("Expected no binary spikes, actual %v (%v).", spikes, err)
+	}
+}
+
+func TestReadSpikesErrors(t *testing.T) {
+	var buf bytes.Buffer
+	sw := NewBinarySpikeWriter(&buf)
+	sw.WriteSpike(exampleSpikes[0])
+	sw.Flush()
+	valid := buf.String()
+
+	csv_tests := []string{
+		"neuron,label,time\n",
+		"id,label,time\nthree,,10\n",
+		"id,label,time\n3,,soon\n",
+		"id,label,time\n3,10\n",
+	}
+	for _, text := range csv_tests {
+		if _, err := ReadCSVSpikes(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected an error reading CSV %q.", text)
+		}
+	}
+	binary_tests := []string{
+		"go-neuron/events\x01",
+		SPIKES_FORMAT + "\x02",
+		SPIKES_FORMAT,
+		// Truncated within the spike.
+		valid[:len(valid)-1],
+	}
+	for _, text := range binary_tests {
+		if _, err := ReadBinarySpikes(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected an error reading binary %q.", text)
+		}
+	}
+}
+
+func TestSpikeWriterTap(t *testing.T) {
+	// Record the spikes of a chain of neurons simulated with the
+	// writer as the network's tap.
+	var csv_buf, binary_buf bytes.Buffer
+	csv_writer, binary_writer := NewCSVSpikeWriter(&csv_buf), NewBinarySpikeWriter(&binary_buf)
+	net := NewNetwork(1)
+	net.Tap = func(ae ActivationEvent) {
+		csv_writer.Tap(ae)
+		binary_writer.Tap(ae)
+	}
+	for i := 0; i < 3; i++ {
+		net.AddNeuron(action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+	}
+	net.Connect(1, 2, 1, time.Millisecond)
+	net.Connect(2, 3, 1, time.Millisecond)
+	net.Registry().SetLabel(3, "last")
+	start := time.Unix(0, 0)
+
+	net.Neuron(1).AddPotentialAt(0, start)
+	net.Simulation().Run()
+
+	expected := []Spike{
+		{1, "", start},
+		{2, "", start.Add(time.Millisecond)},
+		{3, "last", start.Add(2 * time.Millisecond)},
+	}
+	for _, sw := range []interface{ Flush() error }{csv_writer, binary_writer} {
+		if err := sw.Flush(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	spikes, err := ReadCSVSpikes(&csv_buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	checkSpikes(t, "CSV tap", expected, spikes, true)
+	spikes, err = ReadBinarySpikes(&binary_buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	checkSpikes(t, "binary tap", expected, spikes, false)
+}
+
+func TestProcessOptionsTap(t *testing.T) {
+	// A tap on a processed stream sees the events received from the
+	// stream and those scheduled directly.
+	activation_stream := make(ActivationStream, 1)
+	var registry Registry
+	last := &Neuron{
+		ActivationStream: &activation_stream,
+		ActionPotential:  action_potential.NewAlwaysFirer(new(action_potential.Simple)),
+	}
+	first := makeNeuronWithTerminal(last, time.Millisecond, &activation_stream,
+		action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+	registry.Register(first)
+	registry.Register(last)
+	var ids []NeuronID
+
+	first.AddPotentialAt(0, time.Now())
+	activation_stream.ProcessWithOptions(ProcessOptions{
+		StopWhenEmpty: true,
+		Tap:           func(ae ActivationEvent) { ids = append(ids, ae.ID) },
+	})
+
+	if len(ids) != 2 || ids[0] != first.ID || ids[1] != last.ID {
+		t.Errorf("Expected the tap to see neurons %d and %d, actual %v.", first.ID, last.ID, ids)
+	}
+}
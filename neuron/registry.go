@@ -0,0 +1,123 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"fmt"
+)
+
+// A NeuronID identifies a neuron within a Registry. IDs are assigned
+// in the order neurons are registered, starting from 1, and are never
+// reused, so neurons built in the same order in different runs have
+// the same IDs. Neurons which are not registered have the zero ID.
+type NeuronID int
+
+// A Registry assigns neurons their IDs and resolves IDs and labels
+// back to neurons. The zero value is ready to use.
+type Registry struct {
+	neurons []*Neuron
+	labels  map[string]NeuronID
+}
+
+// Register assigns the neuron the next ID, returning it, and records
+// the neuron's Label if it has one. It returns an error if the neuron
+// is already registered, here or in another Registry, or its label is
+// in use.
+func (r *Registry) Register(n *Neuron) (NeuronID, error) {
+	if r.contains(n) {
+		return n.ID, fmt.Errorf("neuron %d is already registered", n.ID)
+	}
+	if n.registry != nil {
+		return 0, fmt.Errorf("neuron %d is registered in another registry", n.ID)
+	}
+	if _, ok := r.labels[n.Label]; ok && n.Label != "" {
+		return 0, fmt.Errorf("label %q is already in use", n.Label)
+	}
+	r.neurons = append(r.neurons, n)
+	n.ID, n.registry = NeuronID(len(r.neurons)), r
+	if n.Label != "" {
+		r.setLabel(n, n.Label)
+	}
+	return n.ID, nil
+}
+
+func (r *Registry) setLabel(n *Neuron, label string) {
+	if r.labels == nil {
+		r.labels = make(map[string]NeuronID)
+	}
+	delete(r.labels, n.Label)
+	n.Label = label
+	r.labels[label] = n.ID
+}
+
+// SetLabel changes the label of the neuron with the given ID. It
+// returns an error if there is no such neuron or the label is used by
+// another neuron. The empty label removes the neuron's label.
+func (r *Registry) SetLabel(id NeuronID, label string) error {
+	n := r.Neuron(id)
+	if n == nil {
+		return fmt.Errorf("unknown neuron %d", id)
+	}
+	if other, ok := r.labels[label]; ok && other != id {
+		return fmt.Errorf("label %q is already in use", label)
+	}
+	if label == "" {
+		delete(r.labels, n.Label)
+		n.Label = ""
+		return nil
+	}
+	r.setLabel(n, label)
+	return nil
+}
+
+// Neuron returns the neuron with the given ID, or nil if there is no
+// such neuron.
+func (r *Registry) Neuron(id NeuronID) *Neuron {
+	if id < 1 || int(id) > len(r.neurons) {
+		return nil
+	}
+	return r.neurons[id-1]
+}
+
+// Lookup returns the neuron with the given label, or nil if there is
+// no such neuron.
+func (r *Registry) Lookup(label string) *Neuron {
+	id, ok := r.labels[label]
+	if !ok {
+		return nil
+	}
+	return r.Neuron(id)
+}
+
+// Len returns the number of registered neurons.
+func (r *Registry) Len() int {
+	return len(r.neurons)
+}
+
+// contains returns whether the neuron is registered.
+func (r *Registry) contains(n *Neuron) bool {
+	return n != nil && n.registry == r
+}
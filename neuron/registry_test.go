@@ -0,0 +1,153 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"github.com/absoludity/go-neuron/action_potential"
+	"testing"
+	"time"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	var r Registry
+	neurons := []*Neuron{{Label: "first"}, {}, {Label: "third"}}
+	for i, n := range neurons {
+		id, err := r.Register(n)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if id != NeuronID(i+1) || n.ID != NeuronID(i+1) {
+			t.Errorf("Expected ID %d, actual %d (neuron has %d).", i+1, id, n.ID)
+		}
+		if r.Neuron(id) != n {
+			t.Errorf("Expected neuron %d to be resolved from its ID.", i)
+		}
+	}
+	if r.Len() != 3 {
+		t.Errorf("Expected 3 neurons, actual %d.", r.Len())
+	}
+	if r.Lookup("third") != neurons[2] || r.Lookup("second") != nil {
+		t.Errorf("Expected only registered labels to be resolved.")
+	}
+	if r.Neuron(0) != nil || r.Neuron(4) != nil {
+		t.Errorf("Expected no neuron for unknown IDs.")
+	}
+
+	if _, err := r.Register(neurons[1]); err == nil {
+		t.Errorf("Expected an error registering a neuron twice.")
+	}
+	if _, err := r.Register(&Neuron{Label: "first"}); err == nil {
+		t.Errorf("Expected an error registering a label twice.")
+	}
+	if r.Len() != 3 {
+		t.Errorf("Expected failed registrations not to be added, actual %d neurons.", r.Len())
+	}
+}
+
+func TestRegistryUnregisteredID(t *testing.T) {
+	var r Registry
+	unregistered := &Neuron{}
+	first := &Neuron{}
+	r.Register(first)
+
+	if first.ID == unregistered.ID {
+		t.Errorf("Expected the first registered neuron to have an ID other than %d.",
+			unregistered.ID)
+	}
+	if r.Neuron(unregistered.ID) != nil || r.contains(unregistered) {
+		t.Errorf("Expected the unregistered neuron not to be resolved.")
+	}
+}
+
+func TestRegistryRejectsOtherRegistry(t *testing.T) {
+	var first, second Registry
+	n := &Neuron{}
+	first.Register(&Neuron{})
+	id, _ := first.Register(n)
+
+	if _, err := second.Register(n); err == nil {
+		t.Errorf("Expected an error registering a neuron from another registry.")
+	}
+	if second.Len() != 0 || n.ID != id || first.Neuron(id) != n {
+		t.Errorf("Expected the neuron to remain only in its own registry.")
+	}
+}
+
+func TestRegistrySetLabel(t *testing.T) {
+	var r Registry
+	first, _ := r.Register(&Neuron{Label: "first"})
+	second, _ := r.Register(&Neuron{})
+
+	if err := r.SetLabel(second, "first"); err == nil {
+		t.Errorf("Expected an error reusing a label.")
+	}
+	if err := r.SetLabel(7, "seventh"); err == nil {
+		t.Errorf("Expected an error labelling an unknown neuron.")
+	}
+	if err := r.SetLabel(first, "renamed"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if r.Lookup("first") != nil || r.Lookup("renamed") != r.Neuron(first) || r.Neuron(first).Label != "renamed" {
+		t.Errorf("Expected the label to be changed.")
+	}
+	if err := r.SetLabel(first, ""); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if r.Lookup("renamed") != nil || r.Neuron(first).Label != "" {
+		t.Errorf("Expected the label to be removed.")
+	}
+}
+
+func TestEventsCarryNeuronIdentity(t *testing.T) {
+	net := NewNetwork(1)
+	net.AddNeuron(new(action_potential.Simple))
+	id := net.AddNeuron(action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+	if err := net.Registry().SetLabel(id, "pacemaker"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	n := net.Registry().Lookup("pacemaker")
+	n.Axon.Delay = time.Millisecond
+	n.Axon.Terminals = []action_potential.ActionPotential{new(action_potential.Simple)}
+	now := time.Now()
+
+	n.AddPotentialAt(0, now)
+	ae := <-*net.Stream()
+
+	if ae.Neuron != n || ae.ID != id || ae.Label != "pacemaker" {
+		t.Errorf("Expected an activation event for neuron %d labelled pacemaker, actual %d labelled %q.",
+			id, ae.ID, ae.Label)
+	}
+	var queue HeapScheduler
+	schedule(&queue, ae)
+	te := queue.Pop()
+	if te == nil {
+		t.Fatalf("Expected a terminal event to be scheduled.")
+	}
+	if te.ID != id || te.Label != "pacemaker" || te.Time != now.Add(time.Millisecond) {
+		t.Errorf("Expected a terminal event for neuron %d labelled pacemaker, actual %d labelled %q.",
+			id, te.ID, te.Label)
+	}
+}
@@ -0,0 +1,157 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+// A Scheduler holds the terminal events waiting to be delivered,
+// ordered by time. Terminal events with the same time are returned
+// in the order they were pushed.
+type Scheduler interface {
+	// Push adds a terminal event to the scheduler.
+	Push(te *TerminalEvent)
+	// Peek returns the earliest terminal event without removing it,
+	// or nil if the scheduler is empty.
+	Peek() *TerminalEvent
+	// Pop removes and returns the earliest terminal event, or nil if
+	// the scheduler is empty.
+	Pop() *TerminalEvent
+	// Len returns the number of terminal events in the scheduler.
+	Len() int
+}
+
+// An OrderedList is a Scheduler which keeps terminal events in a
+// sorted list. Each insertion scans the list, so it is only suitable
+// for small numbers of pending events; see the scheduler benchmarks.
+type OrderedList struct {
+	list.List
+}
+
+func (l *OrderedList) Insert(value interface{}) *list.Element {
+	// Insert after any events at the same time, so that ties are
+	// delivered in the order they were inserted.
+	event_time := value.(*TerminalEvent).Time
+	for e := l.Front(); e != nil; e = e.Next() {
+		if e.Value.(*TerminalEvent).Time.After(event_time) {
+			return l.InsertBefore(value, e)
+		}
+	}
+	return l.PushBack(value)
+}
+
+func (l *OrderedList) Push(te *TerminalEvent) {
+	l.Insert(te)
+}
+
+func (l *OrderedList) Peek() *TerminalEvent {
+	e := l.Front()
+	if e == nil {
+		return nil
+	}
+	return e.Value.(*TerminalEvent)
+}
+
+func (l *OrderedList) Pop() *TerminalEvent {
+	e := l.Front()
+	if e == nil {
+		return nil
+	}
+	return l.Remove(e).(*TerminalEvent)
+}
+
+// A HeapScheduler is a Scheduler backed by a binary heap, taking
+// O(log n) time to push or pop a terminal event. The zero value is
+// ready to use.
+type HeapScheduler struct {
+	events terminalEventHeap
+	// The sequence number of the next pushed event, which breaks
+	// ties between events at the same time.
+	next uint64
+}
+
+func (h *HeapScheduler) Push(te *TerminalEvent) {
+	heap.Push(&h.events, sequencedEvent{te, h.next})
+	h.next += 1
+}
+
+func (h *HeapScheduler) Peek() *TerminalEvent {
+	if len(h.events) == 0 {
+		return nil
+	}
+	return h.events[0].event
+}
+
+func (h *HeapScheduler) Pop() *TerminalEvent {
+	if len(h.events) == 0 {
+		return nil
+	}
+	return heap.Pop(&h.events).(sequencedEvent).event
+}
+
+func (h *HeapScheduler) Len() int {
+	return len(h.events)
+}
+
+type sequencedEvent struct {
+	event    *TerminalEvent
+	sequence uint64
+}
+
+// terminalEventHeap implements heap.Interface, ordering events by
+// time and then by sequence.
+type terminalEventHeap []sequencedEvent
+
+func (h terminalEventHeap) Len() int {
+	return len(h)
+}
+
+func (h terminalEventHeap) Less(i, j int) bool {
+	if h[i].event.Time.Equal(h[j].event.Time) {
+		return h[i].sequence < h[j].sequence
+	}
+	return h[i].event.Time.Before(h[j].event.Time)
+}
+
+func (h terminalEventHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *terminalEventHeap) Push(x interface{}) {
+	*h = append(*h, x.(sequencedEvent))
+}
+
+func (h *terminalEventHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	// Clear the reference so the event can be garbage collected.
+	old[n-1] = sequencedEvent{}
+	*h = old[:n-1]
+	return x
+}
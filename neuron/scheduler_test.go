@@ -0,0 +1,141 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var schedulers = []struct {
+	name string
+	new  func() Scheduler
+}{
+	{"OrderedList", func() Scheduler { return new(OrderedList) }},
+	{"HeapScheduler", func() Scheduler { return new(HeapScheduler) }},
+}
+
+func TestSchedulerOrdersByTime(t *testing.T) {
+	start := time.Now()
+	offsets := []int{5, 1, 4, 2, 3, 0}
+	for _, s := range schedulers {
+		queue := s.new()
+		for _, offset := range offsets {
+			queue.Push(&TerminalEvent{Time: start.Add(time.Duration(offset) * time.Millisecond)})
+		}
+		if queue.Len() != len(offsets) {
+			t.Errorf("%s: Expected %d events, actual %d.", s.name, len(offsets), queue.Len())
+		}
+		for i := range offsets {
+			expected := start.Add(time.Duration(i) * time.Millisecond)
+			if peeked := queue.Peek(); peeked == nil || !peeked.Time.Equal(expected) {
+				t.Errorf("%s: Expected to peek event at %s, actual %v.", s.name, expected, peeked)
+			}
+			if popped := queue.Pop(); popped == nil || !popped.Time.Equal(expected) {
+				t.Errorf("%s: Expected to pop event at %s, actual %v.", s.name, expected, popped)
+			}
+		}
+		if queue.Peek() != nil || queue.Pop() != nil {
+			t.Errorf("%s: Expected an empty scheduler to return nil.", s.name)
+		}
+	}
+}
+
+func TestSchedulerTiesAreFirstInFirstOut(t *testing.T) {
+	// Events at the same time must come out in the order they were
+	// pushed, even when interleaved with events at other times.
+	start := time.Now()
+	for _, s := range schedulers {
+		queue := s.new()
+		var expected []*TerminalEvent
+		for i := 0; i < 100; i++ {
+			queue.Push(&TerminalEvent{Time: start.Add(time.Millisecond)})
+			te := &TerminalEvent{Time: start, Delay: time.Duration(i)}
+			queue.Push(te)
+			expected = append(expected, te)
+		}
+		for i, te := range expected {
+			if popped := queue.Pop(); popped != te {
+				t.Errorf("%s: Expected event %d to be popped in order, actual %v.",
+					s.name, i, popped)
+				break
+			}
+		}
+	}
+}
+
+// benchmarkScheduler measures the cost of delivering an event while
+// the given number are pending, by repeatedly popping the earliest
+// and pushing a replacement at a random delay after it.
+func benchmarkScheduler(b *testing.B, queue Scheduler, pending int) {
+	rng := rand.New(rand.NewSource(1))
+	start := time.Now()
+	max_delay := int64(time.Second)
+	events := make([]*TerminalEvent, pending)
+	for i := range events {
+		events[i] = &TerminalEvent{Time: start.Add(time.Duration(rng.Int63n(max_delay)))}
+	}
+	if l, ok := queue.(*OrderedList); ok {
+		// Filling the list with Insert is quadratic, so add the
+		// events in order directly.
+		sortTerminalEvents(events)
+		for _, te := range events {
+			l.PushBack(te)
+		}
+	} else {
+		for _, te := range events {
+			queue.Push(te)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		te := queue.Pop()
+		te.Time = te.Time.Add(time.Duration(rng.Int63n(max_delay)))
+		queue.Push(te)
+	}
+}
+
+func sortTerminalEvents(events []*TerminalEvent) {
+	var h HeapScheduler
+	for _, te := range events {
+		h.Push(te)
+	}
+	for i := range events {
+		events[i] = h.Pop()
+	}
+}
+
+func BenchmarkScheduler(b *testing.B) {
+	for _, s := range schedulers {
+		for _, pending := range []int{1e3, 1e4, 1e5, 1e6} {
+			b.Run(fmt.Sprintf("%s/%d", s.name, pending), func(b *testing.B) {
+				benchmarkScheduler(b, s.new(), pending)
+			})
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"fmt"
+	"github.com/absoludity/go-neuron/action_potential"
+	"math"
+	"time"
+)
+
+// ShortTermPlasticity is the Tsodyks-Markram model of a dynamic
+// synapse. Each signal uses a fraction of the synapse's resources,
+// which recover over time, while the fraction used builds up with
+// repeated signals and decays back to U. Depending on the parameters
+// the effective weight of a train of signals depresses, facilitates
+// or both.
+//
+// http://www.scholarpedia.org/article/Short-term_synaptic_plasticity
+type ShortTermPlasticity struct {
+	// U is the fraction (0-1] of the resources used by a signal
+	// reaching a rested synapse.
+	U float64
+	// The time constants for the recovery of resources and the
+	// decay of facilitation. Without a facilitation time constant
+	// the synapse only depresses.
+	TauRecovery     time.Duration
+	TauFacilitation time.Duration
+}
+
+// Validate returns an error describing the first problem with the
+// dynamics, or nil if they are valid.
+func (p ShortTermPlasticity) Validate() error {
+	switch {
+	case !(p.U > 0 && p.U <= 1):
+		return fmt.Errorf("utilisation %g must be in (0,1]", p.U)
+	case p.TauRecovery <= 0:
+		return fmt.Errorf("recovery time constant %s must be positive", p.TauRecovery)
+	case p.TauFacilitation < 0:
+		return fmt.Errorf("facilitation time constant %s must not be negative", p.TauFacilitation)
+	}
+	return nil
+}
+
+// SetDynamics gives the synapse the dynamics, or returns an error
+// without changing it if they are not valid.
+func (s *Synapse) SetDynamics(dynamics ShortTermPlasticity) error {
+	if err := dynamics.Validate(); err != nil {
+		return err
+	}
+	s.Dynamics = &dynamics
+	return nil
+}
+
+// Typical depressing and facilitating synapses between cortical
+// pyramidal neurons and interneurons, from Gupta et al. (2000).
+var (
+	DEPRESSING_SYNAPSE   = ShortTermPlasticity{U: 0.5, TauRecovery: 800 * time.Millisecond}
+	FACILITATING_SYNAPSE = ShortTermPlasticity{
+		U:               0.16,
+		TauRecovery:     45 * time.Millisecond,
+		TauFacilitation: 376 * time.Millisecond,
+	}
+)
+
+// decay returns exp(-interval/tau), or zero without a time constant.
+func decay(interval, tau time.Duration) float64 {
+	if tau <= 0 {
+		return 0
+	}
+	return math.Exp(-float64(interval) / float64(tau))
+}
+
+// transmit returns the potential added to the target by a signal
+// reaching the synapse at the given time. With Dynamics this is the
+// Weight scaled by the resources released, relative to a rested
+// synapse, so the first signal of a train adds the full Weight.
+func (s *Synapse) transmit(t time.Time) action_potential.Potential {
+	dynamics := s.Dynamics
+	if dynamics == nil {
+		return s.Weight
+	}
+	if s.last_use.IsZero() {
+		s.utilisation = dynamics.U
+		s.resources = 1
+	} else {
+		interval := t.Sub(s.last_use)
+		if interval < 0 {
+			interval = 0
+		}
+		u, x := s.utilisation, s.resources
+		s.utilisation = dynamics.U + u*(1-dynamics.U)*decay(interval, dynamics.TauFacilitation)
+		s.resources = 1 + (x-x*u-1)*decay(interval, dynamics.TauRecovery)
+	}
+	s.last_use = t
+	return action_potential.Potential(float64(s.Weight) * s.utilisation * s.resources / dynamics.U)
+}
@@ -0,0 +1,148 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"github.com/absoludity/go-neuron/action_potential"
+	"testing"
+	"time"
+)
+
+// transmitTrain returns the potential transmitted by each of n
+// signals reaching the synapse at the given interval.
+func transmitTrain(s *Synapse, start time.Time, interval time.Duration, n int) []action_potential.Potential {
+	transmitted := make([]action_potential.Potential, n)
+	for i := 0; i < n; i++ {
+		transmitted[i] = s.transmit(start.Add(time.Duration(i) * interval))
+	}
+	return transmitted
+}
+
+func TestShortTermDepression(t *testing.T) {
+	now := time.Now()
+	s := &Synapse{Weight: DEFAULT_WEIGHT, Dynamics: &DEPRESSING_SYNAPSE}
+
+	transmitted := transmitTrain(s, now, 20*time.Millisecond, 5)
+
+	if transmitted[0] != DEFAULT_WEIGHT {
+		t.Errorf("Expected the first signal to transmit %.1f, actual %.3f.",
+			DEFAULT_WEIGHT, transmitted[0])
+	}
+	for i := 1; i < len(transmitted); i++ {
+		if transmitted[i] >= transmitted[i-1] {
+			t.Errorf("Expected depression, but signal %d transmitted %.3f "+
+				"after %.3f.", i, transmitted[i], transmitted[i-1])
+		}
+	}
+
+	// The resources recover after a long pause.
+	recovered := s.transmit(now.Add(time.Minute))
+	if !approximately(recovered, DEFAULT_WEIGHT) {
+		t.Errorf("Expected a recovered synapse to transmit %.1f, actual %.3f.",
+			DEFAULT_WEIGHT, recovered)
+	}
+}
+
+func TestShortTermFacilitation(t *testing.T) {
+	now := time.Now()
+	s := &Synapse{Weight: DEFAULT_WEIGHT, Dynamics: &FACILITATING_SYNAPSE}
+
+	transmitted := transmitTrain(s, now, 20*time.Millisecond, 3)
+
+	for i := 1; i < len(transmitted); i++ {
+		if transmitted[i] <= transmitted[i-1] {
+			t.Errorf("Expected facilitation, but signal %d transmitted %.3f "+
+				"after %.3f.", i, transmitted[i], transmitted[i-1])
+		}
+	}
+}
+
+func TestStaticSynapse(t *testing.T) {
+	s := &Synapse{Weight: DEFAULT_WEIGHT}
+
+	transmitted := transmitTrain(s, time.Now(), time.Millisecond, 3)
+
+	for i, p := range transmitted {
+		if p != DEFAULT_WEIGHT {
+			t.Errorf("%d: Expected %.1f, actual %.3f.", i, DEFAULT_WEIGHT, p)
+		}
+	}
+}
+
+func TestSetDynamicsValidates(t *testing.T) {
+	invalid := []func(*ShortTermPlasticity){
+		func(p *ShortTermPlasticity) { p.U = 0 },
+		func(p *ShortTermPlasticity) { p.U = -0.5 },
+		func(p *ShortTermPlasticity) { p.U = 1.5 },
+		func(p *ShortTermPlasticity) { p.TauRecovery = 0 },
+		func(p *ShortTermPlasticity) { p.TauRecovery = -time.Millisecond },
+		func(p *ShortTermPlasticity) { p.TauFacilitation = -time.Millisecond },
+	}
+
+	for i, modify := range invalid {
+		dynamics := FACILITATING_SYNAPSE
+		modify(&dynamics)
+		s := &Synapse{Weight: DEFAULT_WEIGHT}
+
+		err := s.SetDynamics(dynamics)
+
+		if err == nil || s.Dynamics != nil {
+			t.Errorf("%d: Expected an error for dynamics %+v.", i, dynamics)
+		}
+	}
+
+	for _, dynamics := range []ShortTermPlasticity{DEPRESSING_SYNAPSE, FACILITATING_SYNAPSE} {
+		s := &Synapse{Weight: DEFAULT_WEIGHT}
+		if err := s.SetDynamics(dynamics); err != nil || *s.Dynamics != dynamics {
+			t.Errorf("Expected dynamics %+v, actual %v (%v).", dynamics, s.Dynamics, err)
+		}
+	}
+}
+
+func TestProcessShortTermDepression(t *testing.T) {
+	as := make(ActivationStream, 3)
+	now := time.Now()
+	recorder := action_potential.NewEventRecorder(new(action_potential.Simple))
+	n := &Neuron{}
+	n.Axon.Connect(recorder, DEFAULT_WEIGHT).Dynamics = &DEPRESSING_SYNAPSE
+	for i := 0; i < 3; i++ {
+		as <- ActivationEvent{Time: now.Add(time.Duration(i) * time.Millisecond), Neuron: n}
+	}
+	close(as)
+
+	as.Process()
+
+	if len(recorder.Events) != 3 {
+		t.Fatalf("Expected 3 calls to AddPotential, received %d.",
+			len(recorder.Events))
+	}
+	for i := 1; i < len(recorder.Events); i++ {
+		if recorder.Events[i].Potential >= recorder.Events[i-1].Potential {
+			t.Errorf("Expected depression, but signal %d added %.3f after %.3f.",
+				i, recorder.Events[i].Potential, recorder.Events[i-1].Potential)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"time"
+)
+
+// A Simulation processes an activation stream in virtual time. Rather
+// than waiting for each terminal event to be due, as Process does, it
+// delivers them in time order as fast as possible, so identical input
+// always produces identical output regardless of the speed of the
+// machine. Terminal events at the same time are delivered in the
+// order they were scheduled.
+//
+// Potential must be added with AddPotentialAt, at times no earlier
+// than Now, for the simulation to be deterministic. Neurons on the
+// simulation's stream which fire while it runs are scheduled directly,
+// so the stream only needs to buffer the activation events added
+// between runs.
+type Simulation struct {
+	// Tap, if set, is called with each activation event as it is
+	// scheduled.
+	Tap func(ActivationEvent)
+
+	stream *ActivationStream
+	queue  HeapScheduler
+	now    time.Time
+	// wakes holds the wake events scheduled in the queue.
+	wakes map[*Neuron]time.Time
+}
+
+func NewSimulation(as *ActivationStream) *Simulation {
+	return &Simulation{stream: as, wakes: make(map[*Neuron]time.Time)}
+}
+
+// Now returns the virtual time of the most recently delivered
+// terminal event.
+func (sim *Simulation) Now() time.Time {
+	return sim.now
+}
+
+// Pending returns the number of terminal events which are scheduled
+// but not yet delivered.
+func (sim *Simulation) Pending() int {
+	pending := 0
+	for _, se := range sim.queue.events {
+		if !se.event.wake {
+			pending += 1
+		}
+	}
+	return pending
+}
+
+// receive schedules all the activation events waiting on the stream,
+// and the wakes waiting in its outbox, without blocking.
+func (sim *Simulation) receive(d delivery) {
+	d.collect()
+	for {
+		select {
+		case ae, ok := <-*sim.stream:
+			if !ok {
+				return
+			}
+			d.emit(ae)
+		default:
+			return
+		}
+	}
+}
+
+// RunUntil delivers the terminal events due at or before the given
+// time, including those caused by the deliveries themselves, and
+// returns the number delivered. Later events remain scheduled for the
+// next run.
+func (sim *Simulation) RunUntil(until time.Time) int {
+	delivered := 0
+	d := scheduleTo(sim.stream, &sim.queue, sim.wakes, sim.Tap)
+	defer d.close()
+	for {
+		sim.receive(d)
+		te := sim.queue.Peek()
+		if te == nil || te.Time.After(until) {
+			return delivered
+		}
+		sim.queue.Pop()
+		if te.Time.After(sim.now) {
+			sim.now = te.Time
+		}
+		d.deliver(te)
+		if !te.wake {
+			delivered += 1
+		}
+	}
+}
+
+// end_of_time is later than any terminal event.
+var end_of_time = time.Unix(1<<62, 0)
+
+// Run delivers terminal events until none remain, returning the number
+// delivered. For a network with ongoing activity, use RunUntil.
+func (sim *Simulation) Run() int {
+	return sim.RunUntil(end_of_time)
+}
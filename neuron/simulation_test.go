@@ -0,0 +1,279 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"github.com/absoludity/go-neuron/action_potential"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSimulationChain(t *testing.T) {
+	// The virtual time equivalent of TestActivationStreamDelay, which
+	// completes without waiting and without any variance.
+	activation_stream := make(ActivationStream, 1)
+	axon_delay := time.Duration(100) * time.Microsecond
+	event_recorder := action_potential.NewEventRecorder(
+		new(action_potential.Simple))
+	prev := makeNeuronWithTerminal(event_recorder, axon_delay, &activation_stream,
+		action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+	for i := 0; i < 999; i++ {
+		prev = makeNeuronWithTerminal(prev, axon_delay, &activation_stream,
+			action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+	}
+	sim := NewSimulation(&activation_stream)
+	started_at := time.Unix(0, 0)
+
+	prev.AddPotentialAt(0, started_at)
+	delivered := sim.Run()
+
+	if delivered != 1000 {
+		t.Errorf("Expected 1000 terminal events, delivered %d.", delivered)
+	}
+	if len(event_recorder.Events) != 1 {
+		t.Fatalf("Expected 1 event, got %d.", len(event_recorder.Events))
+	}
+	expected_time := started_at.Add(1000 * axon_delay)
+	if event_recorder.Events[0].Time != expected_time {
+		t.Errorf("Expected event at %s, got %s.",
+			expected_time, event_recorder.Events[0].Time)
+	}
+	if sim.Now() != expected_time {
+		t.Errorf("Expected simulation time %s, got %s.", expected_time, sim.Now())
+	}
+}
+
+func TestSimulationRunUntil(t *testing.T) {
+	as := make(ActivationStream, 5)
+	start := time.Unix(0, 0)
+	fake := action_potential.NewEventRecorder(new(action_potential.Simple))
+	for i := 1; i <= 5; i++ {
+		as <- ActivationEvent{Time: start, Neuron: makeNeuronWithTerminal(fake, time.Duration(i)*time.Second, nil, nil)}
+	}
+	sim := NewSimulation(&as)
+
+	delivered := sim.RunUntil(start.Add(3 * time.Second))
+
+	if delivered != 3 || len(fake.Events) != 3 {
+		t.Errorf("Expected 3 deliveries, got %d (%d events).", delivered, len(fake.Events))
+	}
+	if sim.Pending() != 2 {
+		t.Errorf("Expected 2 pending terminal events, got %d.", sim.Pending())
+	}
+	if sim.Now() != start.Add(3*time.Second) {
+		t.Errorf("Expected simulation time %s, got %s.", start.Add(3*time.Second), sim.Now())
+	}
+
+	delivered = sim.Run()
+
+	if delivered != 2 || sim.Pending() != 0 {
+		t.Errorf("Expected the remaining 2 deliveries, got %d (%d pending).",
+			delivered, sim.Pending())
+	}
+}
+
+func TestSimulationSelfExcitation(t *testing.T) {
+	// A neuron connected to itself three times fires three more
+	// activation events for each one, more than the stream can buffer.
+	activation_stream := make(ActivationStream, 1)
+	n := &Neuron{
+		ActivationStream: &activation_stream,
+		ActionPotential:  action_potential.NewAlwaysFirer(new(action_potential.Simple)),
+	}
+	for i := 0; i < 3; i++ {
+		n.ConnectTo(n, 1, time.Millisecond)
+	}
+	sim := NewSimulation(&activation_stream)
+	start := time.Unix(0, 0)
+
+	n.AddPotentialAt(0, start)
+	delivered := sim.RunUntil(start.Add(3 * time.Millisecond))
+
+	if delivered != 1+3+9 {
+		t.Errorf("Expected 13 terminal events, delivered %d.", delivered)
+	}
+	if sim.Pending() != 27 {
+		t.Errorf("Expected 27 pending terminal events, got %d.", sim.Pending())
+	}
+}
+
+func TestSimulationSpontaneousSpike(t *testing.T) {
+	// Hodgkin-Huxley and Izhikevich neurons spike some time after their
+	// input, when nothing else is delivered to them, and the spike still
+	// reaches the terminal at the time of the crossing.
+	hh := action_potential.NewHodgkinHuxley(action_potential.SQUID_GIANT_AXON)
+	iz := action_potential.NewIzhikevich(action_potential.REGULAR_SPIKING)
+	test_cases := []struct {
+		model interface {
+			action_potential.Spontaneous
+			LastSpike() time.Time
+		}
+		input action_potential.Potential
+	}{
+		{hh, 20},
+		{iz, 40},
+	}
+
+	for _, tc := range test_cases {
+		activation_stream := make(ActivationStream, 1)
+		event_recorder := action_potential.NewEventRecorder(
+			new(action_potential.Simple))
+		axon_delay := time.Millisecond
+		n := makeNeuronWithTerminal(event_recorder, axon_delay, &activation_stream, tc.model)
+		sim := NewSimulation(&activation_stream)
+		start := time.Unix(0, 0)
+		tc.model.GetPotentialAt(start)
+
+		n.AddPotentialAt(tc.input, start)
+		delivered := sim.Run()
+
+		if delivered != 1 {
+			t.Errorf("%T: Expected 1 terminal event, delivered %d.", tc.model, delivered)
+		}
+		if len(event_recorder.Events) != 1 {
+			t.Fatalf("%T: Expected 1 event, got %d.", tc.model, len(event_recorder.Events))
+		}
+		expected_time := tc.model.LastSpike().Add(axon_delay)
+		if !tc.model.LastSpike().After(start) || event_recorder.Events[0].Time != expected_time {
+			t.Errorf("%T: Expected event at %s, got %s.",
+				tc.model, expected_time, event_recorder.Events[0].Time)
+		}
+	}
+}
+
+func TestSimulationSpontaneousRepetitiveSpikes(t *testing.T) {
+	// Driven by a constant current, each spike is delivered separately
+	// at the time of its own crossing.
+	activation_stream := make(ActivationStream, 1)
+	event_recorder := action_potential.NewEventRecorder(
+		new(action_potential.Simple))
+	hh := action_potential.NewHodgkinHuxley(action_potential.SQUID_GIANT_AXON)
+	hh.Current = 10
+	n := makeNeuronWithTerminal(event_recorder, 0, &activation_stream, hh)
+	sim := NewSimulation(&activation_stream)
+	start := time.Unix(0, 0)
+	hh.GetPotentialAt(start)
+
+	n.AddPotentialAt(0, start)
+	sim.RunUntil(start.Add(100 * time.Millisecond))
+
+	events := event_recorder.Events
+	if len(events) < 5 || len(events) > 9 {
+		t.Fatalf("Expected between 5 and 9 spikes in 100ms, got %d.", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		interval := events[i].Time.Sub(events[i-1].Time)
+		if interval < 10*time.Millisecond || interval > 20*time.Millisecond {
+			t.Errorf("Expected spikes between 10ms and 20ms apart, got %s.", interval)
+		}
+	}
+	if events[len(events)-1].Time != hh.LastSpike() {
+		t.Errorf("Expected the last event at the last spike %s, got %s.",
+			hh.LastSpike(), events[len(events)-1].Time)
+	}
+}
+
+// simulateRandomNetwork builds a recurrent network from the seed,
+// stimulates it and returns the events recorded by each neuron.
+func simulateRandomNetwork(seed int64) [][]action_potential.AddPotentialEvent {
+	rng := rand.New(rand.NewSource(seed))
+	size := 50
+	net := NewNetwork(size)
+	recorders := make([]*action_potential.EventRecorder, size)
+	for i := range recorders {
+		recorders[i] = action_potential.NewEventRecorder(new(action_potential.Simple))
+		net.AddNeuron(recorders[i])
+	}
+	for i := 0; i < size*5; i++ {
+		pre, post := NeuronID(rng.Intn(size)+1), NeuronID(rng.Intn(size)+1)
+		weight := action_potential.Potential(rng.Float64()*20 - 4)
+		delay := time.Duration(rng.Intn(5000)+500) * time.Microsecond
+		net.Connect(pre, post, weight, delay)
+	}
+	sim := net.Simulation()
+	start := time.Unix(0, 0)
+	for i := 0; i < 100; i++ {
+		at := start.Add(time.Duration(i) * 10 * time.Millisecond)
+		sim.RunUntil(at)
+		net.Neuron(NeuronID(rng.Intn(size)+1)).AddPotentialAt(20, at)
+	}
+	sim.RunUntil(start.Add(2 * time.Second))
+
+	events := make([][]action_potential.AddPotentialEvent, size)
+	for i, r := range recorders {
+		events[i] = r.Events
+	}
+	return events
+}
+
+func TestSimulationIsDeterministic(t *testing.T) {
+	first := simulateRandomNetwork(42)
+	second := simulateRandomNetwork(42)
+
+	total := 0
+	for i := range first {
+		if len(first[i]) != len(second[i]) {
+			t.Fatalf("Neuron %d: Expected %d events, got %d.",
+				i, len(first[i]), len(second[i]))
+		}
+		for j := range first[i] {
+			a, b := first[i][j], second[i][j]
+			if a.Potential != b.Potential || a.Time != b.Time ||
+				a.Result != b.Result || a.Fired != b.Fired {
+				t.Errorf("Neuron %d event %d: Expected %v, got %v.", i, j, a, b)
+			}
+		}
+		total += len(first[i])
+	}
+	if total <= 100 {
+		t.Errorf("Expected activity beyond the stimulus, got %d events.", total)
+	}
+}
+
+func TestSimulationPendingExcludesWakes(t *testing.T) {
+	// A neuron waiting to be woken for its predicted spike has no
+	// terminal event pending.
+	activation_stream := make(ActivationStream, 1)
+	event_recorder := action_potential.NewEventRecorder(
+		new(action_potential.Simple))
+	hh := action_potential.NewHodgkinHuxley(action_potential.SQUID_GIANT_AXON)
+	n := makeNeuronWithTerminal(event_recorder, 0, &activation_stream, hh)
+	sim := NewSimulation(&activation_stream)
+	start := time.Unix(0, 0)
+	hh.GetPotentialAt(start)
+
+	n.AddPotentialAt(20, start)
+	sim.RunUntil(start)
+
+	if sim.Pending() != 0 {
+		t.Errorf("Expected no pending terminal events, got %d.", sim.Pending())
+	}
+	if delivered := sim.Run(); delivered != 1 || sim.Pending() != 0 {
+		t.Errorf("Expected 1 delivered and none pending, got %d and %d.",
+			delivered, sim.Pending())
+	}
+}
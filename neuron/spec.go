@@ -0,0 +1,402 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/absoludity/go-neuron/action_potential"
+	"io"
+	"time"
+)
+
+// NETWORK_FORMAT identifies a JSON network specification, which is
+// versioned with action_potential.ENCODING_VERSION.
+const NETWORK_FORMAT = "go-neuron/network"
+
+// A NetworkSpec describes a network as populations of neurons and the
+// projections connecting them, so that it can be kept in a file and
+// edited without writing Go. Durations are written as strings such
+// as "1.5ms".
+type NetworkSpec struct {
+	Format      string           `json:"format"`
+	Version     int              `json:"version"`
+	Populations []PopulationSpec `json:"populations"`
+	Projections []ProjectionSpec `json:"projections,omitempty"`
+}
+
+// A PopulationSpec describes a named group of neurons sharing a model.
+type PopulationSpec struct {
+	Name  string    `json:"name"`
+	Size  int       `json:"size"`
+	Model ModelSpec `json:"model"`
+	// AxonDelay is the Delay of each neuron's Axon.
+	AxonDelay string `json:"axon_delay,omitempty"`
+}
+
+// A ModelSpec describes the action potential of a neuron. Type is
+// "simple", "lif" or "izhikevich", and Params are the model's
+// parameters:
+//
+//	simple:     the SimpleParams JSON, or none for DEFAULT_SIMPLE_PARAMS
+//	lif:        {"time_constant": "10ms"}
+//	izhikevich: {"a": 0.02, "b": 0.2, "c": -65, "d": 8, "current": 10}
+type ModelSpec struct {
+	Type   string          `json:"type"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// A ProjectionSpec connects the neurons of the Pre population to those
+// of the Post population, following the Rule and then adding the
+// explicit Connections.
+type ProjectionSpec struct {
+	Pre  string `json:"pre"`
+	Post string `json:"post"`
+	// Rule is "all_to_all", which connects each neuron to every
+	// other, "one_to_one", which connects populations of the same
+	// size by index, or empty for only the explicit Connections.
+	Rule string `json:"rule,omitempty"`
+	// Weight and Delay are used for the synapses added by the Rule.
+	// The nil Weight uses DEFAULT_WEIGHT.
+	Weight      *action_potential.Potential `json:"weight,omitempty"`
+	Delay       string                      `json:"delay,omitempty"`
+	Connections []ConnectionSpec            `json:"connections,omitempty"`
+}
+
+// A ConnectionSpec describes a synapse between neurons identified by
+// their index within the projection's populations.
+type ConnectionSpec struct {
+	Pre    int                        `json:"pre"`
+	Post   int                        `json:"post"`
+	Weight action_potential.Potential `json:"weight"`
+	Delay  string                     `json:"delay,omitempty"`
+}
+
+type lifParamsJSON struct {
+	TimeConstant string `json:"time_constant,omitempty"`
+}
+
+type izhikevichParamsJSON struct {
+	A       float64 `json:"a"`
+	B       float64 `json:"b"`
+	C       float64 `json:"c"`
+	D       float64 `json:"d"`
+	Current float64 `json:"current,omitempty"`
+}
+
+// parseDuration parses a duration string, with the empty string
+// being no duration.
+func parseDuration(text string) (time.Duration, error) {
+	if text == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(text)
+}
+
+// formatDuration formats a duration for parseDuration, omitting
+// zero durations.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// decodeParams decodes the params into v, rejecting unknown fields so
+// that misspelt parameters are not silently ignored.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// newModel returns a new action potential described by the spec.
+func (spec ModelSpec) newModel() (action_potential.ActionPotential, error) {
+	switch spec.Type {
+	case "simple":
+		params := action_potential.DEFAULT_SIMPLE_PARAMS
+		if len(spec.Params) > 0 {
+			if err := decodeParams(spec.Params, &params); err != nil {
+				return nil, err
+			}
+		}
+		return action_potential.NewSimple(params)
+	case "lif":
+		var params lifParamsJSON
+		if len(spec.Params) > 0 {
+			if err := decodeParams(spec.Params, &params); err != nil {
+				return nil, err
+			}
+		}
+		time_constant, err := parseDuration(params.TimeConstant)
+		if err != nil {
+			return nil, err
+		}
+		return &action_potential.LeakyIntegrateAndFire{TimeConstant: time_constant}, nil
+	case "izhikevich":
+		var params izhikevichParamsJSON
+		if err := decodeParams(spec.Params, &params); err != nil {
+			return nil, err
+		}
+		model := action_potential.NewIzhikevich(action_potential.IzhikevichParams{
+			A: params.A, B: params.B, C: params.C, D: params.D,
+		})
+		model.Current = params.Current
+		return model, nil
+	}
+	return nil, fmt.Errorf("unknown model type %q", spec.Type)
+}
+
+// modelSpec returns the spec describing the model's parameters.
+func modelSpec(model action_potential.ActionPotential) (ModelSpec, error) {
+	var spec ModelSpec
+	var params interface{}
+	switch m := model.(type) {
+	case *action_potential.Simple:
+		spec.Type, params = "simple", m.Params()
+	case *action_potential.LeakyIntegrateAndFire:
+		spec.Type, params = "lif", lifParamsJSON{formatDuration(m.TimeConstant)}
+	case *action_potential.Izhikevich:
+		spec.Type, params = "izhikevich", izhikevichParamsJSON{m.A, m.B, m.C, m.D, m.Current}
+	default:
+		return spec, fmt.Errorf("cannot describe model %T", model)
+	}
+	var err error
+	spec.Params, err = json.Marshal(params)
+	return spec, err
+}
+
+// ReadNetworkSpec decodes a network specification from the reader.
+func ReadNetworkSpec(r io.Reader) (*NetworkSpec, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var spec NetworkSpec
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, err
+	}
+	if spec.Format != NETWORK_FORMAT {
+		return nil, fmt.Errorf("unknown network format %q", spec.Format)
+	}
+	if spec.Version != action_potential.ENCODING_VERSION {
+		return nil, fmt.Errorf("unsupported encoding version %d (expected %d)",
+			spec.Version, action_potential.ENCODING_VERSION)
+	}
+	return &spec, nil
+}
+
+// Write encodes the specification to the writer as indented JSON.
+func (spec *NetworkSpec) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(spec)
+}
+
+// Build returns a new network described by the specification, whose
+// activation stream can buffer the given number of activation events.
+// Each population is added with AddPopulation.
+func (spec *NetworkSpec) Build(buffer int) (*Network, error) {
+	net := NewNetwork(buffer)
+	for _, p := range spec.Populations {
+		if p.Name == "" || net.Population(p.Name) != nil {
+			return nil, fmt.Errorf("population name %q is empty or repeated", p.Name)
+		}
+		if p.Size < 0 {
+			return nil, fmt.Errorf("population %q has negative size %d", p.Name, p.Size)
+		}
+		axon_delay, err := parseDuration(p.AxonDelay)
+		if err != nil {
+			return nil, fmt.Errorf("population %q: %s", p.Name, err)
+		}
+		// Check the model before creating any neurons with it.
+		if _, err := p.Model.newModel(); err != nil {
+			return nil, fmt.Errorf("population %q: %s", p.Name, err)
+		}
+		ids := net.AddPopulation(p.Name, p.Size, func() action_potential.ActionPotential {
+			model, _ := p.Model.newModel()
+			return model
+		})
+		for _, id := range ids {
+			net.Neuron(id).Axon.Delay = axon_delay
+		}
+	}
+	for i, p := range spec.Projections {
+		if err := p.build(net); err != nil {
+			return nil, fmt.Errorf("projection %d from %q to %q: %s", i, p.Pre, p.Post, err)
+		}
+	}
+	return net, nil
+}
+
+func (p ProjectionSpec) build(net *Network) error {
+	pre, post := net.Population(p.Pre), net.Population(p.Post)
+	if pre == nil || post == nil {
+		return fmt.Errorf("unknown population")
+	}
+	weight := DEFAULT_WEIGHT
+	if p.Weight != nil {
+		weight = *p.Weight
+	}
+	delay, err := parseDuration(p.Delay)
+	if err != nil {
+		return err
+	}
+	switch p.Rule {
+	case "":
+	case "all_to_all":
+		for _, pre_id := range pre {
+			for _, post_id := range post {
+				if pre_id != post_id {
+					net.Connect(pre_id, post_id, weight, delay)
+				}
+			}
+		}
+	case "one_to_one":
+		if len(pre) != len(post) {
+			return fmt.Errorf("one_to_one requires populations of the same size")
+		}
+		for i := range pre {
+			net.Connect(pre[i], post[i], weight, delay)
+		}
+	default:
+		return fmt.Errorf("unknown rule %q", p.Rule)
+	}
+	for _, c := range p.Connections {
+		if c.Pre < 0 || c.Pre >= len(pre) || c.Post < 0 || c.Post >= len(post) {
+			return fmt.Errorf("connection from %d to %d is out of range", c.Pre, c.Post)
+		}
+		delay, err := parseDuration(c.Delay)
+		if err != nil {
+			return err
+		}
+		net.Connect(pre[c.Pre], post[c.Post], c.Weight, delay)
+	}
+	return nil
+}
+
+// Spec returns a specification describing the network, which Build
+// turns back into an equivalent network. Neurons added with
+// AddPopulation keep their population, while those added with
+// AddNeuron are each described as a population named after their ID.
+// Every synapse and axon terminal is described as an explicit
+// connection. It returns an error for models which cannot be
+// described, synapses with Plasticity or Dynamics, and terminals
+// outside the network.
+func (net *Network) Spec() (*NetworkSpec, error) {
+	spec := &NetworkSpec{Format: NETWORK_FORMAT, Version: action_potential.ENCODING_VERSION}
+
+	// The population and index within it of each neuron.
+	type member struct{ population, index int }
+	members := make(map[NeuronID]member, len(net.registry.neurons))
+	starts := make(map[NeuronID]population)
+	for _, p := range net.populations {
+		if len(p.ids) > 0 {
+			starts[p.ids[0]] = p
+		}
+	}
+	names := make(map[string]bool)
+	for id := NeuronID(1); int(id) <= len(net.registry.neurons); {
+		p, ok := starts[id]
+		if !ok || names[p.name] {
+			p = population{fmt.Sprintf("neuron%d", id), []NeuronID{id}}
+		}
+		if names[p.name] {
+			return nil, fmt.Errorf("population name %q is repeated", p.name)
+		}
+		names[p.name] = true
+		ps, err := net.populationSpec(p)
+		if err != nil {
+			return nil, err
+		}
+		for i, member_id := range p.ids {
+			members[member_id] = member{len(spec.Populations), i}
+		}
+		spec.Populations = append(spec.Populations, ps)
+		id += NeuronID(len(p.ids))
+	}
+
+	// Projections are added in the order their first connection is
+	// found.
+	projections := make(map[[2]int]int)
+	connect := func(pre *Neuron, target action_potential.ActionPotential, weight action_potential.Potential, delay time.Duration) error {
+		pre_id := pre.ID
+		post, ok := target.(*Neuron)
+		if !ok || !net.registry.contains(post) {
+			return fmt.Errorf("neuron %d is connected outside the network", pre_id)
+		}
+		from, to := members[pre_id], members[post.ID]
+		key := [2]int{from.population, to.population}
+		i, ok := projections[key]
+		if !ok {
+			i = len(spec.Projections)
+			projections[key] = i
+			spec.Projections = append(spec.Projections, ProjectionSpec{
+				Pre:  spec.Populations[from.population].Name,
+				Post: spec.Populations[to.population].Name,
+			})
+		}
+		spec.Projections[i].Connections = append(spec.Projections[i].Connections,
+			ConnectionSpec{from.index, to.index, weight, formatDuration(delay)})
+		return nil
+	}
+	for _, n := range net.registry.neurons {
+		for _, terminal := range n.Axon.Terminals {
+			if err := connect(n, terminal, DEFAULT_WEIGHT, 0); err != nil {
+				return nil, err
+			}
+		}
+		for _, s := range n.Axon.Synapses {
+			if s.Plasticity != nil || s.Dynamics != nil {
+				return nil, fmt.Errorf("cannot describe the plasticity of neuron %d's synapses",
+					n.ID)
+			}
+			if err := connect(n, s.Target, s.Weight, s.Delay); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return spec, nil
+}
+
+// populationSpec describes the population, whose neurons must share
+// the same model parameters and axon delay.
+func (net *Network) populationSpec(p population) (PopulationSpec, error) {
+	ps := PopulationSpec{Name: p.name, Size: len(p.ids)}
+	for i, id := range p.ids {
+		n := net.registry.Neuron(id)
+		model, err := modelSpec(n.ActionPotential)
+		if err != nil {
+			return ps, fmt.Errorf("neuron %d: %s", id, err)
+		}
+		axon_delay := formatDuration(n.Axon.Delay)
+		if i == 0 {
+			ps.Model, ps.AxonDelay = model, axon_delay
+		} else if model.Type != ps.Model.Type || !bytes.Equal(model.Params, ps.Model.Params) || axon_delay != ps.AxonDelay {
+			return ps, fmt.Errorf("neurons in population %q differ", p.name)
+		}
+	}
+	return ps, nil
+}
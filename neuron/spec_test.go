@@ -0,0 +1,239 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"bytes"
+	"github.com/absoludity/go-neuron/action_potential"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const exampleSpec = `{
+  "format": "go-neuron/network",
+  "version": 1,
+  "populations": [
+    {"name": "input", "size": 3, "model": {"type": "simple"}, "axon_delay": "1ms"},
+    {"name": "leaky", "size": 3, "model": {"type": "lif", "params": {"time_constant": "20ms"}}},
+    {"name": "output", "size": 1, "model": {"type": "izhikevich",
+      "params": {"a": 0.02, "b": 0.2, "c": -65, "d": 8, "current": 5}}}
+  ],
+  "projections": [
+    {"pre": "input", "post": "leaky", "rule": "one_to_one", "weight": 2.5, "delay": "500µs"},
+    {"pre": "leaky", "post": "leaky", "rule": "all_to_all"},
+    {"pre": "leaky", "post": "output", "connections": [
+      {"pre": 2, "post": 0, "weight": -1, "delay": "2ms"}
+    ]}
+  ]
+}`
+
+func TestNetworkSpecBuild(t *testing.T) {
+	spec, err := ReadNetworkSpec(strings.NewReader(exampleSpec))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	net, err := spec.Build(10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	input, leaky, output := net.Population("input"), net.Population("leaky"), net.Population("output")
+	if len(input) != 3 || len(leaky) != 3 || len(output) != 1 || net.Len() != 7 {
+		t.Fatalf("Expected populations of 3, 3 and 1, actual %d, %d and %d.",
+			len(input), len(leaky), len(output))
+	}
+	if _, ok := net.Neuron(input[0]).ActionPotential.(*action_potential.Simple); !ok {
+		t.Errorf("Expected a Simple input neuron.")
+	}
+	if net.Neuron(input[0]).Axon.Delay != time.Millisecond {
+		t.Errorf("Expected an axon delay of 1ms, actual %s.", net.Neuron(input[0]).Axon.Delay)
+	}
+	if lif, ok := net.Neuron(leaky[0]).ActionPotential.(*action_potential.LeakyIntegrateAndFire); !ok || lif.TimeConstant != 20*time.Millisecond {
+		t.Errorf("Expected a leaky neuron with a 20ms time constant.")
+	}
+	if iz, ok := net.Neuron(output[0]).ActionPotential.(*action_potential.Izhikevich); !ok || iz.IzhikevichParams != action_potential.REGULAR_SPIKING || iz.Current != 5 {
+		t.Errorf("Expected a regular spiking Izhikevich output neuron with current 5.")
+	}
+
+	tests := []struct {
+		pre      NeuronID
+		expected []Synapse
+	}{
+		{input[1], []Synapse{{Weight: 2.5, Delay: 500 * time.Microsecond}}},
+		{leaky[0], []Synapse{{Weight: DEFAULT_WEIGHT}, {Weight: DEFAULT_WEIGHT}}},
+		{leaky[2], []Synapse{{Weight: DEFAULT_WEIGHT}, {Weight: DEFAULT_WEIGHT}, {Weight: -1, Delay: 2 * time.Millisecond}}},
+		{output[0], nil},
+	}
+	for _, tt := range tests {
+		synapses := net.Neuron(tt.pre).Axon.Synapses
+		if len(synapses) != len(tt.expected) {
+			t.Errorf("Expected neuron %d to have %d synapses, actual %d.",
+				tt.pre, len(tt.expected), len(synapses))
+			continue
+		}
+		for i, s := range synapses {
+			if s.Weight != tt.expected[i].Weight || s.Delay != tt.expected[i].Delay {
+				t.Errorf("Expected synapse %d of neuron %d to have weight %.1f and delay %s, actual %.1f and %s.",
+					i, tt.pre, tt.expected[i].Weight, tt.expected[i].Delay, s.Weight, s.Delay)
+			}
+		}
+	}
+}
+
+func TestNetworkSpecRoundTrip(t *testing.T) {
+	net := NewNetwork(10)
+	params := action_potential.DEFAULT_SIMPLE_PARAMS
+	params.Threshold = 8
+	excitatory := net.AddPopulation("excitatory", 4, func() action_potential.ActionPotential {
+		model, _ := action_potential.NewSimple(params)
+		return model
+	})
+	single := net.AddNeuron(&action_potential.LeakyIntegrateAndFire{})
+	for _, id := range excitatory {
+		net.Neuron(id).Axon.Delay = 3 * time.Millisecond
+		net.Connect(id, single, 1.5, time.Duration(id)*time.Millisecond)
+	}
+	net.Neuron(single).Axon.Terminals = []action_potential.ActionPotential{net.Neuron(excitatory[0])}
+
+	spec, err := net.Spec()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := spec.Write(&buf); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	read, err := ReadNetworkSpec(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	built, err := read.Build(10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	rebuilt_spec, err := built.Spec()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(spec, rebuilt_spec) {
+		t.Errorf("Expected the rebuilt network to have the same spec.\nExpected: %+v\nActual:   %+v",
+			spec, rebuilt_spec)
+	}
+	names := []string{spec.Populations[0].Name, spec.Populations[1].Name}
+	if !reflect.DeepEqual(names, []string{"excitatory", "neuron5"}) {
+		t.Errorf("Expected populations excitatory and neuron5, actual %v.", names)
+	}
+	if simple := built.Neuron(1).ActionPotential.(*action_potential.Simple); simple.Params() != params {
+		t.Errorf("Expected params %v, actual %v.", params, simple.Params())
+	}
+	// The axon terminal is rebuilt as a synapse.
+	expected := [][]NeuronID{{4}, {4}, {4}, {4}, {0}}
+	if !reflect.DeepEqual(connections(built), expected) {
+		t.Errorf("Expected connections %v, actual %v.", expected, connections(built))
+	}
+}
+
+func TestReadNetworkSpecErrors(t *testing.T) {
+	tests := []string{
+		`{"format": "other", "version": 1}`,
+		`{"format": "go-neuron/network", "version": 2}`,
+		`{"format": "go-neuron/network", "version": 1, "neurons": []}`,
+	}
+	for _, text := range tests {
+		if _, err := ReadNetworkSpec(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected an error reading %s", text)
+		}
+	}
+}
+
+func TestNetworkSpecBuildErrors(t *testing.T) {
+	simple := ModelSpec{Type: "simple"}
+	tests := []struct {
+		name string
+		spec NetworkSpec
+	}{
+		{"repeated population", NetworkSpec{Populations: []PopulationSpec{
+			{Name: "a", Size: 1, Model: simple}, {Name: "a", Size: 1, Model: simple}}}},
+		{"negative size", NetworkSpec{Populations: []PopulationSpec{
+			{Name: "a", Size: -1, Model: simple}}}},
+		{"unknown model", NetworkSpec{Populations: []PopulationSpec{
+			{Name: "a", Size: 1, Model: ModelSpec{Type: "unknown"}}}}},
+		{"unknown param", NetworkSpec{Populations: []PopulationSpec{
+			{Name: "a", Size: 1, Model: ModelSpec{Type: "lif", Params: []byte(`{"tau": "1ms"}`)}}}}},
+		{"invalid params", NetworkSpec{Populations: []PopulationSpec{
+			{Name: "a", Size: 1, Model: ModelSpec{Type: "simple", Params: []byte(
+				`{"threshold": 10, "peak": 5, "refractory": -2, "decay_duration": "1ms",
+				"active_duration": "1ms", "inactive_duration": "1ms"}`)}}}}},
+		{"unknown population", NetworkSpec{
+			Populations: []PopulationSpec{{Name: "a", Size: 1, Model: simple}},
+			Projections: []ProjectionSpec{{Pre: "a", Post: "b"}}}},
+		{"unknown rule", NetworkSpec{
+			Populations: []PopulationSpec{{Name: "a", Size: 1, Model: simple}},
+			Projections: []ProjectionSpec{{Pre: "a", Post: "a", Rule: "some"}}}},
+		{"one_to_one sizes", NetworkSpec{
+			Populations: []PopulationSpec{{Name: "a", Size: 1, Model: simple}, {Name: "b", Size: 2, Model: simple}},
+			Projections: []ProjectionSpec{{Pre: "a", Post: "b", Rule: "one_to_one"}}}},
+		{"connection out of range", NetworkSpec{
+			Populations: []PopulationSpec{{Name: "a", Size: 1, Model: simple}},
+			Projections: []ProjectionSpec{{Pre: "a", Post: "a", Connections: []ConnectionSpec{{Pre: 0, Post: 1}}}}}},
+	}
+	for _, tt := range tests {
+		if _, err := tt.spec.Build(1); err == nil {
+			t.Errorf("%s: Expected an error.", tt.name)
+		}
+	}
+}
+
+func TestNetworkSpecErrors(t *testing.T) {
+	firer := NewNetwork(1)
+	firer.AddNeuron(action_potential.NewAlwaysFirer(new(action_potential.Simple)))
+
+	plastic := NewNetwork(1)
+	a, b := plastic.AddNeuron(new(action_potential.Simple)), plastic.AddNeuron(new(action_potential.Simple))
+	s, _ := plastic.Connect(a, b, 1, 0)
+	s.Plasticity = &DEFAULT_STDP
+
+	outside := NewNetwork(1)
+	outside.AddNeuron(new(action_potential.Simple))
+	outside.Neuron(1).Axon.Connect(new(action_potential.Simple), 1)
+
+	tests := []struct {
+		name string
+		net  *Network
+	}{
+		{"unknown model", firer},
+		{"plasticity", plastic},
+		{"outside the network", outside},
+	}
+	for _, tt := range tests {
+		if _, err := tt.net.Spec(); err == nil {
+			t.Errorf("%s: Expected an error.", tt.name)
+		}
+	}
+}
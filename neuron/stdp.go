@@ -0,0 +1,126 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"fmt"
+	"github.com/absoludity/go-neuron/action_potential"
+	"math"
+	"time"
+)
+
+// STDP is a pair-based spike-timing-dependent plasticity rule. A
+// synapse's weight is potentiated when the signal reaches it before
+// the post-synaptic neuron fires, and depressed when it arrives
+// after, by an amount which decays exponentially with the interval
+// between the two. Only the nearest pair of pre- and post-synaptic
+// events is considered.
+//
+// http://www.scholarpedia.org/article/Spike-timing_dependent_plasticity
+type STDP struct {
+	// The maximum potentiation and depression of the weight.
+	APlus, AMinus action_potential.Potential
+	// The time constants of potentiation and depression.
+	TauPlus, TauMinus time.Duration
+	// The bounds of the weight.
+	MinWeight, MaxWeight action_potential.Potential
+}
+
+// DEFAULT_STDP depresses slightly more than it potentiates, which
+// keeps the weights of uncorrelated inputs from growing, and bounds
+// the weights between zero and twice DEFAULT_WEIGHT.
+var DEFAULT_STDP = STDP{
+	APlus:     0.1,
+	AMinus:    0.105,
+	TauPlus:   20 * time.Millisecond,
+	TauMinus:  20 * time.Millisecond,
+	MinWeight: 0,
+	MaxWeight: 2 * DEFAULT_WEIGHT,
+}
+
+// Validate returns an error describing the first problem with the
+// rule, or nil if it is valid.
+func (rule STDP) Validate() error {
+	switch {
+	case rule.TauPlus <= 0:
+		return fmt.Errorf("potentiation time constant %s must be positive", rule.TauPlus)
+	case rule.TauMinus <= 0:
+		return fmt.Errorf("depression time constant %s must be positive", rule.TauMinus)
+	case rule.MaxWeight < rule.MinWeight:
+		return fmt.Errorf("maximum weight %.1f must not be below the "+
+			"minimum weight %.1f", rule.MaxWeight, rule.MinWeight)
+	}
+	return nil
+}
+
+// SetPlasticity gives the synapse the rule, or returns an error
+// without changing it if the rule is not valid.
+func (s *Synapse) SetPlasticity(rule STDP) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	s.Plasticity = &rule
+	return nil
+}
+
+// change returns the amplitude decayed over the interval.
+func change(amplitude action_potential.Potential, interval, tau time.Duration) action_potential.Potential {
+	return action_potential.Potential(float64(amplitude) * math.Exp(-float64(interval)/float64(tau)))
+}
+
+func (rule *STDP) clamp(weight action_potential.Potential) action_potential.Potential {
+	if weight < rule.MinWeight {
+		return rule.MinWeight
+	}
+	if weight > rule.MaxWeight {
+		return rule.MaxWeight
+	}
+	return weight
+}
+
+// arrived depresses the synapse if the post-synaptic neuron last
+// fired before the signal arrived at the given time, then records
+// the arrival.
+func (s *Synapse) arrived(t time.Time) {
+	rule := s.Plasticity
+	if rule == nil || s.post == nil {
+		return
+	}
+	if last_fired := s.post.last_fired; !last_fired.IsZero() && !last_fired.After(t) {
+		s.Weight = rule.clamp(s.Weight - change(rule.AMinus, t.Sub(last_fired), rule.TauMinus))
+	}
+	s.last_arrival = t
+}
+
+// postSynapticFired potentiates the synapse if the signal last
+// arrived before the post-synaptic neuron fired at the given time.
+func (s *Synapse) postSynapticFired(t time.Time) {
+	rule := s.Plasticity
+	if rule == nil || s.last_arrival.IsZero() || s.last_arrival.After(t) {
+		return
+	}
+	s.Weight = rule.clamp(s.Weight + change(rule.APlus, t.Sub(s.last_arrival), rule.TauPlus))
+}
@@ -0,0 +1,171 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"github.com/absoludity/go-neuron/action_potential"
+	"math"
+	"testing"
+	"time"
+)
+
+// makePlasticPair returns a pre-synaptic neuron connected to a
+// post-synaptic neuron by a synapse using the given rule. Both always
+// fire on added potential.
+func makePlasticPair(rule *STDP, weight action_potential.Potential) (*Neuron, *Neuron, *Synapse) {
+	as := make(ActivationStream, 10)
+	pre := &Neuron{
+		ActivationStream: &as,
+		ActionPotential:  action_potential.NewAlwaysFirer(new(action_potential.Simple)),
+	}
+	post := &Neuron{
+		ActivationStream: &as,
+		ActionPotential:  action_potential.NewAlwaysFirer(new(action_potential.Simple)),
+	}
+	s := pre.ConnectTo(post, weight, 0)
+	s.Plasticity = rule
+	return pre, post, s
+}
+
+func approximately(a, b action_potential.Potential) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestSTDP(t *testing.T) {
+	now := time.Now()
+	interval := 10 * time.Millisecond
+	potentiation := action_potential.Potential(float64(DEFAULT_STDP.APlus) * math.Exp(-0.5))
+	depression := action_potential.Potential(float64(DEFAULT_STDP.AMinus) * math.Exp(-0.5))
+	cases := []struct {
+		pre, post time.Time
+		expected  action_potential.Potential
+	}{
+		// Pre-synaptic arrival before the post-synaptic neuron fires
+		// potentiates.
+		{now, now.Add(interval), DEFAULT_WEIGHT + potentiation},
+		// Pre-synaptic arrival after the post-synaptic neuron fires
+		// depresses.
+		{now.Add(interval), now, DEFAULT_WEIGHT - depression},
+	}
+
+	for i, tt := range cases {
+		_, post, s := makePlasticPair(&DEFAULT_STDP, DEFAULT_WEIGHT)
+		if tt.pre.Before(tt.post) {
+			s.arrived(tt.pre)
+			post.AddPotentialAt(0, tt.post)
+		} else {
+			post.AddPotentialAt(0, tt.post)
+			s.arrived(tt.pre)
+		}
+
+		if !approximately(s.Weight, tt.expected) {
+			t.Errorf("%d: Expected weight %f, actual %f.", i, tt.expected, s.Weight)
+		}
+	}
+}
+
+func TestSTDPBounds(t *testing.T) {
+	now := time.Now()
+	_, post, s := makePlasticPair(&DEFAULT_STDP, DEFAULT_STDP.MaxWeight)
+
+	s.arrived(now)
+	post.AddPotentialAt(0, now)
+
+	if s.Weight != DEFAULT_STDP.MaxWeight {
+		t.Errorf("Expected weight bounded at %f, actual %f.",
+			DEFAULT_STDP.MaxWeight, s.Weight)
+	}
+
+	s.Weight = DEFAULT_STDP.MinWeight
+	s.arrived(now.Add(time.Millisecond))
+
+	if s.Weight != DEFAULT_STDP.MinWeight {
+		t.Errorf("Expected weight bounded at %f, actual %f.",
+			DEFAULT_STDP.MinWeight, s.Weight)
+	}
+}
+
+func TestSTDPFixedWithoutPlasticity(t *testing.T) {
+	now := time.Now()
+	_, post, s := makePlasticPair(nil, DEFAULT_WEIGHT)
+
+	s.arrived(now)
+	post.AddPotentialAt(0, now.Add(time.Millisecond))
+	s.arrived(now.Add(2 * time.Millisecond))
+
+	if s.Weight != DEFAULT_WEIGHT {
+		t.Errorf("Expected weight to remain %f, actual %f.", DEFAULT_WEIGHT, s.Weight)
+	}
+}
+
+func TestSetPlasticityValidates(t *testing.T) {
+	invalid := []func(*STDP){
+		func(rule *STDP) { rule.TauPlus = 0 },
+		func(rule *STDP) { rule.TauPlus = -time.Millisecond },
+		func(rule *STDP) { rule.TauMinus = 0 },
+		func(rule *STDP) { rule.MaxWeight = rule.MinWeight - 1 },
+	}
+
+	for i, modify := range invalid {
+		rule := DEFAULT_STDP
+		modify(&rule)
+		_, _, s := makePlasticPair(nil, DEFAULT_WEIGHT)
+
+		err := s.SetPlasticity(rule)
+
+		if err == nil || s.Plasticity != nil {
+			t.Errorf("%d: Expected an error for rule %+v.", i, rule)
+		}
+	}
+
+	_, _, s := makePlasticPair(nil, DEFAULT_WEIGHT)
+	if err := s.SetPlasticity(DEFAULT_STDP); err != nil || *s.Plasticity != DEFAULT_STDP {
+		t.Errorf("Expected rule %+v, actual %v (%v).", DEFAULT_STDP, s.Plasticity, err)
+	}
+}
+
+func TestSTDPOnlineDuringProcess(t *testing.T) {
+	// The post-synaptic neuron fires as the signal arrives, so the
+	// synapse is potentiated by the full amplitude.
+	now := time.Now()
+	pre, post, s := makePlasticPair(&DEFAULT_STDP, DEFAULT_WEIGHT)
+	as := make(ActivationStream, 1)
+	pre.ActivationStream = &as
+	pre.Axon.Delay = time.Millisecond
+
+	pre.AddPotentialAt(0, now)
+	close(as)
+	as.Process()
+
+	if !approximately(s.Weight, DEFAULT_WEIGHT+DEFAULT_STDP.APlus) {
+		t.Errorf("Expected weight %f, actual %f.",
+			DEFAULT_WEIGHT+DEFAULT_STDP.APlus, s.Weight)
+	}
+	if post.last_fired != now.Add(time.Millisecond) {
+		t.Errorf("Expected post-synaptic neuron to fire at %s, actual %s.",
+			now.Add(time.Millisecond), post.last_fired)
+	}
+}
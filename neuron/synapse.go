@@ -0,0 +1,102 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"github.com/absoludity/go-neuron/action_potential"
+	"sort"
+	"time"
+)
+
+// DEFAULT_WEIGHT is the potential added to each of an Axon's Terminals
+// when the signal reaches them.
+const DEFAULT_WEIGHT action_potential.Potential = 5.0
+
+// A Synapse connects an axon terminal to a target with a signed
+// weight, which is the potential added to the target when the signal
+// reaches it. Positive weights are excitatory and negative weights
+// inhibitory. The signal reaches the synapse Delay after the Axon's
+// own Delay, so that near and far targets can be reached at
+// different times.
+type Synapse struct {
+	Target action_potential.ActionPotential
+	Weight action_potential.Potential
+	Delay  time.Duration
+	// Plasticity, if set, adjusts the Weight from the timing of
+	// the signals reaching the synapse and of the post-synaptic
+	// neuron firing. It requires the synapse to be added with
+	// Neuron.ConnectTo. SetPlasticity validates the rule first.
+	Plasticity *STDP
+	// Dynamics, if set, scales the Weight of each signal by its
+	// recent use. SetDynamics validates them first.
+	Dynamics *ShortTermPlasticity
+
+	post         *Neuron
+	last_arrival time.Time
+	// The state of the Dynamics when the synapse was last used.
+	utilisation float64
+	resources   float64
+	last_use    time.Time
+}
+
+// Connect adds a synapse to the target with the given weight and no
+// delay beyond the Axon's own.
+func (a *Axon) Connect(target action_potential.ActionPotential, weight action_potential.Potential) *Synapse {
+	return a.ConnectWithDelay(target, weight, 0)
+}
+
+// ConnectWithDelay adds a synapse to the target with the given weight,
+// reached the given delay after the Axon's own. Negative delays are
+// treated as zero.
+func (a *Axon) ConnectWithDelay(target action_potential.ActionPotential,
+	weight action_potential.Potential, delay time.Duration) *Synapse {
+	if delay < 0 {
+		delay = 0
+	}
+	s := &Synapse{Target: target, Weight: weight, Delay: delay}
+	a.Synapses = append(a.Synapses, s)
+	return s
+}
+
+// synapseDelays returns the distinct delays of the axon's terminals
+// and synapses in increasing order. The Terminals have no delay
+// beyond the Axon's own.
+func (a Axon) synapseDelays() []time.Duration {
+	var delays []time.Duration
+	if len(a.Terminals) > 0 {
+		delays = append(delays, 0)
+	}
+	for _, s := range a.Synapses {
+		i := sort.Search(len(delays), func(i int) bool { return delays[i] >= s.Delay })
+		if i < len(delays) && delays[i] == s.Delay {
+			continue
+		}
+		delays = append(delays, 0)
+		copy(delays[i+1:], delays[i:])
+		delays[i] = s.Delay
+	}
+	return delays
+}
@@ -0,0 +1,299 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"fmt"
+	"github.com/absoludity/go-neuron/action_potential"
+	"math/rand"
+	"time"
+)
+
+// A WeightDistribution draws the weight of each generated synapse.
+type WeightDistribution func(rng *rand.Rand) action_potential.Potential
+
+// A DelayDistribution draws the delay of each generated synapse.
+type DelayDistribution func(rng *rand.Rand) time.Duration
+
+// ConstantWeight always draws the given weight.
+func ConstantWeight(weight action_potential.Potential) WeightDistribution {
+	return func(*rand.Rand) action_potential.Potential {
+		return weight
+	}
+}
+
+// UniformWeight draws weights uniformly from [min, max).
+func UniformWeight(min, max action_potential.Potential) WeightDistribution {
+	return func(rng *rand.Rand) action_potential.Potential {
+		return min + action_potential.Potential(rng.Float64())*(max-min)
+	}
+}
+
+// ConstantDelay always draws the given delay.
+func ConstantDelay(delay time.Duration) DelayDistribution {
+	return func(*rand.Rand) time.Duration {
+		return delay
+	}
+}
+
+// UniformDelay draws delays uniformly from [min, max).
+func UniformDelay(min, max time.Duration) DelayDistribution {
+	return func(rng *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(rng.Int63n(int64(max-min)))
+	}
+}
+
+// A Generator builds networks with common topologies, drawing the
+// connections and the weight and delay of each synapse from its Rand,
+// so that the same seed always builds the same network. Neurons are
+// connected with Neuron.ConnectTo, and their IDs start at 1, so the
+// neuron at index i of the n built has ID i+1.
+type Generator struct {
+	// Rand is the source of randomness. The nil value uses a source
+	// seeded with 1.
+	Rand *rand.Rand
+	// Weight draws the weight of each synapse. The nil value uses
+	// DEFAULT_WEIGHT.
+	Weight WeightDistribution
+	// Delay draws the delay of each synapse. The nil value uses no
+	// delay.
+	Delay DelayDistribution
+	// Model returns the action potential of each neuron. The nil
+	// value uses a new Simple.
+	Model func() action_potential.ActionPotential
+	// Buffer is the number of activation events the network's stream
+	// can buffer. The zero value buffers one per neuron, and negative
+	// values are an error.
+	Buffer int
+}
+
+// check returns an error if a network of n neurons cannot be built
+// with the generator.
+func (g Generator) check(n int) error {
+	if n < 0 {
+		return fmt.Errorf("negative number of neurons %d", n)
+	}
+	if g.Buffer < 0 {
+		return fmt.Errorf("negative buffer %d", g.Buffer)
+	}
+	return nil
+}
+
+// newNetwork returns a network of n neurons ready to be connected,
+// along with the generator's source of randomness.
+func (g Generator) newNetwork(n int) (*Network, *rand.Rand) {
+	buffer := g.Buffer
+	if buffer == 0 {
+		buffer = n
+	}
+	net := NewNetwork(buffer)
+	for i := 0; i < n; i++ {
+		if g.Model == nil {
+			net.AddNeuron(new(action_potential.Simple))
+		} else {
+			net.AddNeuron(g.Model())
+		}
+	}
+	rng := g.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	return net, rng
+}
+
+// connect adds a synapse from pre to post with a weight and delay
+// drawn from the generator's distributions.
+func (g Generator) connect(net *Network, rng *rand.Rand, pre, post int) {
+	weight, delay := DEFAULT_WEIGHT, time.Duration(0)
+	if g.Weight != nil {
+		weight = g.Weight(rng)
+	}
+	if g.Delay != nil {
+		delay = g.Delay(rng)
+	}
+	net.registry.neurons[pre].ConnectTo(net.registry.neurons[post], weight, delay)
+}
+
+// AllToAll connects each of n neurons to every other neuron.
+func (g Generator) AllToAll(n int) (*Network, error) {
+	if err := g.check(n); err != nil {
+		return nil, err
+	}
+	net, rng := g.newNetwork(n)
+	for pre := 0; pre < n; pre++ {
+		for post := 0; post < n; post++ {
+			if pre != post {
+				g.connect(net, rng, pre, post)
+			}
+		}
+	}
+	return net, nil
+}
+
+// FixedInDegree connects each of n neurons from k others chosen at
+// random.
+func (g Generator) FixedInDegree(n, k int) (*Network, error) {
+	if err := g.check(n); err != nil {
+		return nil, err
+	}
+	if k < 0 || (n > 0 && k >= n) {
+		return nil, fmt.Errorf("in-degree %d is not between 0 and %d", k, n-1)
+	}
+	net, rng := g.newNetwork(n)
+	for post := 0; post < n; post++ {
+		// Choose from the other neurons by skipping over post.
+		for _, pre := range rng.Perm(n - 1)[:k] {
+			if pre >= post {
+				pre += 1
+			}
+			g.connect(net, rng, pre, post)
+		}
+	}
+	return net, nil
+}
+
+// ErdosRenyi connects each ordered pair of n distinct neurons with
+// probability p.
+func (g Generator) ErdosRenyi(n int, p float64) (*Network, error) {
+	if err := g.check(n); err != nil {
+		return nil, err
+	}
+	if p < 0 || p > 1 {
+		return nil, fmt.Errorf("probability %f is not between 0 and 1", p)
+	}
+	net, rng := g.newNetwork(n)
+	for pre := 0; pre < n; pre++ {
+		for post := 0; post < n; post++ {
+			if pre != post && rng.Float64() < p {
+				g.connect(net, rng, pre, post)
+			}
+		}
+	}
+	return net, nil
+}
+
+// WattsStrogatz builds a small-world network of n neurons. Each is
+// first connected to its k nearest neighbours on a ring, k/2 on each
+// side, and then each of those connections is rewired to a random
+// neuron with probability beta. Connections are undirected, so each
+// is a pair of synapses, one in each direction.
+func (g Generator) WattsStrogatz(n, k int, beta float64) (*Network, error) {
+	if err := g.check(n); err != nil {
+		return nil, err
+	}
+	if k < 0 || k%2 != 0 || (n > 0 && k >= n) {
+		return nil, fmt.Errorf("degree %d is not even and between 0 and %d", k, n-1)
+	}
+	if beta < 0 || beta > 1 {
+		return nil, fmt.Errorf("probability %f is not between 0 and 1", beta)
+	}
+	net, rng := g.newNetwork(n)
+	// Each edge is kept with its near end first, and looked up in
+	// either direction.
+	type edge struct{ near, far int }
+	undirected := func(a, b int) edge {
+		if a > b {
+			return edge{b, a}
+		}
+		return edge{a, b}
+	}
+	// The edges are kept in the order they were added, so that the
+	// synapses are added in the same order for the same seed.
+	var edges []edge
+	exists := make(map[edge]bool)
+	degree := make([]int, n)
+	for i := 0; i < n; i++ {
+		for j := 1; j <= k/2; j++ {
+			e := edge{i, (i + j) % n}
+			edges = append(edges, e)
+			exists[undirected(e.near, e.far)] = true
+			degree[e.near] += 1
+			degree[e.far] += 1
+		}
+	}
+	for i, e := range edges {
+		// Rewire the far end to a neuron which is neither the near
+		// end nor already connected to it, if there is one.
+		if rng.Float64() >= beta || degree[e.near] >= n-1 {
+			continue
+		}
+		far := rng.Intn(n)
+		for far == e.near || exists[undirected(e.near, far)] {
+			far = rng.Intn(n)
+		}
+		delete(exists, undirected(e.near, e.far))
+		exists[undirected(e.near, far)] = true
+		degree[e.far] -= 1
+		degree[far] += 1
+		edges[i].far = far
+	}
+	for _, e := range edges {
+		g.connect(net, rng, e.near, e.far)
+		g.connect(net, rng, e.far, e.near)
+	}
+	return net, nil
+}
+
+// Lattice2D arranges rows*cols neurons on a grid, numbered row by row,
+// so that the neuron in row r and column c, counting from zero, has ID
+// r*cols+c+1. Each is connected to its neighbours above, below, left
+// and right.
+// If periodic, the edges of the grid wrap around to form a torus.
+func (g Generator) Lattice2D(rows, cols int, periodic bool) (*Network, error) {
+	if rows < 0 || cols < 0 {
+		return nil, fmt.Errorf("negative lattice size %dx%d", rows, cols)
+	}
+	if err := g.check(rows * cols); err != nil {
+		return nil, err
+	}
+	net, rng := g.newNetwork(rows * cols)
+	offsets := [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			// Neighbours which wrap onto the same neuron, as in a
+			// lattice only one or two wide, are connected once.
+			connected := make(map[int]bool)
+			for _, offset := range offsets {
+				nr, nc := r+offset[0], c+offset[1]
+				if periodic {
+					nr, nc = (nr+rows)%rows, (nc+cols)%cols
+				} else if nr < 0 || nr >= rows || nc < 0 || nc >= cols {
+					continue
+				}
+				neighbour := nr*cols + nc
+				if neighbour == r*cols+c || connected[neighbour] {
+					continue
+				}
+				connected[neighbour] = true
+				g.connect(net, rng, r*cols+c, neighbour)
+			}
+		}
+	}
+	return net, nil
+}
@@ -0,0 +1,251 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"github.com/absoludity/go-neuron/action_potential"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// connections returns the indices of the neurons each neuron in the
+// network is connected to, in the order the synapses were added, where
+// the neuron with ID 1 has index 0.
+func connections(net *Network) [][]NeuronID {
+	result := make([][]NeuronID, net.Len())
+	for i := range result {
+		for _, s := range net.Neuron(NeuronID(i + 1)).Axon.Synapses {
+			id, _ := net.ID(s.Target.(*Neuron))
+			result[i] = append(result[i], id-1)
+		}
+	}
+	return result
+}
+
+// checkSimple reports any neuron connected to itself, or more than
+// once to another neuron, and returns the in-degree of each neuron.
+func checkSimple(t *testing.T, name string, net *Network) []int {
+	in_degree := make([]int, net.Len())
+	for pre, posts := range connections(net) {
+		seen := make(map[NeuronID]bool)
+		for _, post := range posts {
+			if post == NeuronID(pre) {
+				t.Errorf("%s: Neuron %d is connected to itself.", name, pre)
+			}
+			if seen[post] {
+				t.Errorf("%s: Neuron %d is connected to %d twice.", name, pre, post)
+			}
+			seen[post] = true
+			in_degree[post] += 1
+		}
+	}
+	return in_degree
+}
+
+func countSynapses(net *Network) int {
+	count := 0
+	for _, posts := range connections(net) {
+		count += len(posts)
+	}
+	return count
+}
+
+func TestAllToAll(t *testing.T) {
+	net, err := Generator{}.AllToAll(5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for id, in_degree := range checkSimple(t, "AllToAll", net) {
+		if in_degree != 4 {
+			t.Errorf("Expected neuron %d to have in-degree 4, actual %d.", id, in_degree)
+		}
+	}
+}
+
+func TestFixedInDegree(t *testing.T) {
+	net, err := Generator{Rand: rand.New(rand.NewSource(3))}.FixedInDegree(20, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for id, in_degree := range checkSimple(t, "FixedInDegree", net) {
+		if in_degree != 5 {
+			t.Errorf("Expected neuron %d to have in-degree 5, actual %d.", id, in_degree)
+		}
+	}
+}
+
+func TestErdosRenyi(t *testing.T) {
+	tests := []struct {
+		p        float64
+		min, max int
+	}{
+		{0, 0, 0},
+		{1, 9900, 9900},
+		// The expected 4950 synapses, give or take six standard
+		// deviations.
+		{0.5, 4650, 5250},
+	}
+	for _, tt := range tests {
+		net, err := Generator{Rand: rand.New(rand.NewSource(3))}.ErdosRenyi(100, tt.p)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		checkSimple(t, "ErdosRenyi", net)
+
+		if count := countSynapses(net); count < tt.min || count > tt.max {
+			t.Errorf("Expected between %d and %d synapses with p=%f, actual %d.",
+				tt.min, tt.max, tt.p, count)
+		}
+	}
+}
+
+func TestWattsStrogatz(t *testing.T) {
+	// Without rewiring, each neuron is connected to the two neurons
+	// on either side of it on the ring.
+	net, err := Generator{}.WattsStrogatz(10, 4, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	checkSimple(t, "WattsStrogatz", net)
+	for pre, posts := range connections(net) {
+		for _, post := range posts {
+			distance := (int(post) - pre + 10) % 10
+			if distance != 1 && distance != 2 && distance != 8 && distance != 9 {
+				t.Errorf("Expected neuron %d to be connected to its neighbours, "+
+					"but it is connected to %d.", pre, post)
+			}
+		}
+	}
+
+	// Rewiring keeps the number of connections.
+	for _, beta := range []float64{0.1, 0.5, 1} {
+		net, err = Generator{Rand: rand.New(rand.NewSource(3))}.WattsStrogatz(50, 6, beta)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		checkSimple(t, "WattsStrogatz", net)
+		if count := countSynapses(net); count != 50*6 {
+			t.Errorf("Expected %d synapses with beta=%f, actual %d.", 50*6, beta, count)
+		}
+	}
+}
+
+func TestLattice2D(t *testing.T) {
+	tests := []struct {
+		rows, cols int
+		periodic   bool
+		expected   int
+	}{
+		// Four corners with 2 neighbours, four edges with 3 and the
+		// centre with 4.
+		{3, 3, false, 24},
+		{3, 3, true, 36},
+		{1, 5, false, 8},
+		// Wrapping a single row connects each neuron to its left and
+		// right neighbours, but not to itself.
+		{1, 5, true, 10},
+		{1, 1, true, 0},
+		{2, 2, true, 8},
+	}
+	for _, tt := range tests {
+		net, err := Generator{}.Lattice2D(tt.rows, tt.cols, tt.periodic)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		checkSimple(t, "Lattice2D", net)
+
+		if count := countSynapses(net); count != tt.expected {
+			t.Errorf("Expected %d synapses for a %dx%d lattice (periodic %t), actual %d.",
+				tt.expected, tt.rows, tt.cols, tt.periodic, count)
+		}
+	}
+}
+
+func TestGeneratorIsDeterministic(t *testing.T) {
+	generate := func(seed int64) (*Network, error) {
+		return Generator{
+			Rand:   rand.New(rand.NewSource(seed)),
+			Weight: UniformWeight(-1, 1),
+			Delay:  UniformDelay(time.Millisecond, 2*time.Millisecond),
+			Model: func() action_potential.ActionPotential {
+				return action_potential.NewAlwaysFirer(new(action_potential.Simple))
+			},
+		}.WattsStrogatz(30, 4, 0.3)
+	}
+	first, _ := generate(7)
+	second, _ := generate(7)
+
+	if !reflect.DeepEqual(connections(first), connections(second)) {
+		t.Errorf("Expected the same connections for the same seed.")
+	}
+	for i := 0; i < first.Len(); i++ {
+		for j, s := range first.Neuron(NeuronID(i + 1)).Axon.Synapses {
+			other := second.Neuron(NeuronID(i + 1)).Axon.Synapses[j]
+			if s.Weight != other.Weight || s.Delay != other.Delay {
+				t.Errorf("Expected the same weight and delay for the same seed.")
+			}
+			if s.Weight < -1 || s.Weight >= 1 {
+				t.Errorf("Expected weight in [-1, 1), actual %f.", s.Weight)
+			}
+			if s.Delay < time.Millisecond || s.Delay >= 2*time.Millisecond {
+				t.Errorf("Expected delay in [1ms, 2ms), actual %s.", s.Delay)
+			}
+		}
+	}
+	if _, ok := first.Neuron(1).ActionPotential.(*action_potential.AlwaysFirer); !ok {
+		t.Errorf("Expected the neurons to use the Model.")
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	g := Generator{}
+	negative_buffer := Generator{Buffer: -1}
+	tests := []struct {
+		name     string
+		generate func() (*Network, error)
+	}{
+		{"AllToAll", func() (*Network, error) { return g.AllToAll(-1) }},
+		{"FixedInDegree", func() (*Network, error) { return g.FixedInDegree(5, 5) }},
+		{"FixedInDegree", func() (*Network, error) { return g.FixedInDegree(-1, 0) }},
+		{"ErdosRenyi", func() (*Network, error) { return g.ErdosRenyi(5, 1.5) }},
+		{"ErdosRenyi", func() (*Network, error) { return g.ErdosRenyi(-1, 0.5) }},
+		{"WattsStrogatz", func() (*Network, error) { return g.WattsStrogatz(10, 3, 0.5) }},
+		{"WattsStrogatz", func() (*Network, error) { return g.WattsStrogatz(10, 4, -1) }},
+		{"WattsStrogatz", func() (*Network, error) { return g.WattsStrogatz(-1, 0, 0) }},
+		{"Lattice2D", func() (*Network, error) { return g.Lattice2D(-1, 3, false) }},
+		{"Buffer", func() (*Network, error) { return negative_buffer.AllToAll(5) }},
+		{"Buffer", func() (*Network, error) { return negative_buffer.Lattice2D(2, 3, false) }},
+	}
+	for _, tt := range tests {
+		if net, err := tt.generate(); err == nil || net != nil {
+			t.Errorf("%s: Expected an error for invalid parameters.", tt.name)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/absoludity/go-neuron/action_potential"
+	"io"
+	"strconv"
+	"time"
+)
+
+// A TraceSample records the potential of a neuron at a point in time.
+type TraceSample struct {
+	ID        NeuronID
+	Label     string
+	Time      time.Time
+	Potential action_potential.Potential
+}
+
+// A TraceWriter samples the membrane potential of chosen neurons and
+// saves it as CSV, with a header row followed by a row for each
+// sample of its neuron's ID, label, time in nanoseconds since the
+// Unix epoch and potential.
+//
+// Sampling calls GetPotentialAt on the neurons, so must not happen
+// while they are being processed on another goroutine. With a
+// Simulation, sample between calls to RunUntil.
+type TraceWriter struct {
+	writer         *csv.Writer
+	neurons        []*Neuron
+	header_written bool
+}
+
+func NewTraceWriter(w io.Writer, neurons []*Neuron) *TraceWriter {
+	return &TraceWriter{writer: csv.NewWriter(w), neurons: neurons}
+}
+
+// Sample saves the potential of each of the neurons at the given
+// time, which should not be earlier than the previous sample.
+func (tw *TraceWriter) Sample(t time.Time) error {
+	for _, n := range tw.neurons {
+		err := tw.WriteSample(TraceSample{n.ID, n.Label, t, n.GetPotentialAt(t)})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSample saves the sample, preceded by the header if it is the
+// first.
+func (tw *TraceWriter) WriteSample(s TraceSample) error {
+	if !tw.header_written {
+		if err := tw.writer.Write([]string{"id", "label", "time", "potential"}); err != nil {
+			return err
+		}
+		tw.header_written = true
+	}
+	return tw.writer.Write([]string{
+		strconv.Itoa(int(s.ID)),
+		s.Label,
+		strconv.FormatInt(s.Time.UnixNano(), 10),
+		strconv.FormatFloat(float64(s.Potential), 'g', -1, 32),
+	})
+}
+
+// Flush writes any buffered samples, returning any error from the
+// underlying writer.
+func (tw *TraceWriter) Flush() error {
+	tw.writer.Flush()
+	return tw.writer.Error()
+}
+
+// ReadTrace loads the samples saved by a TraceWriter.
+func ReadTrace(r io.Reader) ([]TraceSample, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	records, err := reader.ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	if header := records[0]; header[0] != "id" || header[1] != "label" || header[2] != "time" || header[3] != "potential" {
+		return nil, fmt.Errorf("unexpected header %q", header)
+	}
+	var samples []TraceSample
+	for i, record := range records[1:] {
+		id, err := strconv.Atoi(record[0])
+		if err != nil {
+			return samples, fmt.Errorf("row %d: invalid id %q", i+1, record[0])
+		}
+		nanoseconds, err := strconv.ParseInt(record[2], 10, 64)
+		if err != nil {
+			return samples, fmt.Errorf("row %d: invalid time %q", i+1, record[2])
+		}
+		potential, err := strconv.ParseFloat(record[3], 32)
+		if err != nil {
+			return samples, fmt.Errorf("row %d: invalid potential %q", i+1, record[3])
+		}
+		samples = append(samples, TraceSample{
+			NeuronID(id), record[1], time.Unix(0, nanoseconds), action_potential.Potential(potential),
+		})
+	}
+	return samples, nil
+}
@@ -0,0 +1,98 @@
+// go-neuron - A neuron simulator for Go.
+//
+// Copyright (c) 2013 - Michael Nelson <absoludity@gmail.com>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this
+//    list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+// ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package neuron
+
+import (
+	"bytes"
+	"github.com/absoludity/go-neuron/action_potential"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceWriter(t *testing.T) {
+	var registry Registry
+	neurons := []*Neuron{
+		{ActionPotential: action_potential.NewLeakyIntegrateAndFire(10 * time.Millisecond), Label: "leaky"},
+		{ActionPotential: new(action_potential.Simple)},
+	}
+	for _, n := range neurons {
+		registry.Register(n)
+	}
+	start := time.Unix(0, 0)
+	for _, n := range neurons {
+		n.ActionPotential.AddPotentialAt(10, start)
+	}
+	var buf bytes.Buffer
+	tw := NewTraceWriter(&buf, neurons)
+
+	var expected []TraceSample
+	for i := 0; i < 3; i++ {
+		sample_time := start.Add(time.Duration(i) * 5 * time.Millisecond)
+		if err := tw.Sample(sample_time); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		for _, n := range neurons {
+			expected = append(expected, TraceSample{n.ID, n.Label, sample_time, n.GetPotentialAt(sample_time)})
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	samples, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(samples) != len(expected) {
+		t.Fatalf("Expected %d samples, actual %d.", len(expected), len(samples))
+	}
+	for i, s := range samples {
+		e := expected[i]
+		if s.ID != e.ID || s.Label != e.Label || !s.Time.Equal(e.Time) || s.Potential != e.Potential {
+			t.Errorf("Expected sample %v, actual %v.", e, s)
+		}
+	}
+	// The leaky neuron's potential decays between samples.
+	if !(samples[0].Potential > samples[2].Potential && samples[2].Potential > samples[4].Potential) {
+		t.Errorf("Expected the leaky potential to decay, actual %.3f, %.3f and %.3f.",
+			samples[0].Potential, samples[2].Potential, samples[4].Potential)
+	}
+}
+
+func TestReadTraceErrors(t *testing.T) {
+	tests := []string{
+		"id,label,time\n",
+		"id,label,time,potential\n1,,10\n",
+		"id,label,time,potential\n1,,10,high\n",
+		"id,label,time,potential\n1,,now,1\n",
+	}
+	for _, text := range tests {
+		if _, err := ReadTrace(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected an error reading %q.", text)
+		}
+	}
+}